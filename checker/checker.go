@@ -0,0 +1,1036 @@
+package checker
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/voidwyrm-2/opal/diagnostics"
+	"github.com/voidwyrm-2/opal/lexer/tokens"
+	"github.com/voidwyrm-2/opal/parser/nodes"
+)
+
+type scope struct {
+	vars   map[string]scheme
+	parent *scope
+	frame  *frame
+}
+
+// frame is what the checker knows about the function whose body is being checked
+type frame struct {
+	params []Type
+	self   Type
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{vars: map[string]scheme{}, parent: parent, frame: nil}
+}
+
+func (s *scope) lookup(name string) (scheme, bool) {
+	for sc := s; sc != nil; sc = sc.parent {
+		if sch, ok := sc.vars[name]; ok {
+			return sch, true
+		}
+	}
+	return scheme{}, false
+}
+
+func (s *scope) nearestFrame() *frame {
+	for sc := s; sc != nil; sc = sc.parent {
+		if sc.frame != nil {
+			return sc.frame
+		}
+	}
+	return nil
+}
+
+/*
+Checker infers the types of a program's expressions, using the `///` signatures of functions where they're given,
+and reports the places where they can't fit together.
+It is deliberately lenient: anything it can't know, such as the elements of a list of mixed types, is `any`.
+*/
+type Checker struct {
+	errs     []error
+	level    int
+	nextID   int
+	global   *scope
+	records  map[string][]string // the fields of each record type, and of every variant of each union
+	unions   map[string][]string // the variants of each union
+	variants map[string]variant
+}
+
+// variant is what the checker knows about one variant of a tagged union
+type variant struct {
+	union  string
+	fields []string
+}
+
+func New() *Checker {
+	prelude := newScope(nil)
+	for _, name := range []string{"say", "print", "len", "head", "tail", "at", "range", "cycle", "map", "filter", "find", "take", "set", "has", "raise", "slice", "bytes"} {
+		prelude.vars[name] = mono(Builtin{Name: name})
+	}
+
+	// the rest of the builtins can be given signatures, the same as Opal functions
+	for name, text := range map[string]string{
+		"div":        "[number, number] -> number",
+		"vector":     "[list<T>] -> vector<T>",
+		"list":       "[vector<T>] -> list<T>",
+		"iterate":    "[T, [T] -> T] -> sequence<T>",
+		"lines":      "[string] -> sequence<string>",
+		"ord":        "[char] -> number",
+		"chr":        "[number] -> char",
+		"get":        "[map<K, V>, K] -> option<V>",
+		"put":        "[map<K, V>, K, V] -> map<K, V>",
+		"delete":     "[map<K, V>, K] -> map<K, V>",
+		"keys":       "[map<K, V>] -> list<K>",
+		"values":     "[map<K, V>] -> list<V>",
+		"merge":      "[map<K, V>, map<K, V>] -> map<K, V>",
+		"grabfile":   "[string] -> string",
+		"text":       "[bytes] -> string",
+		"hex":        "[bytes] -> string",
+		"unhex":      "[string] -> bytes",
+		"base64":     "[bytes] -> string",
+		"unbase64":   "[string] -> bytes",
+		"grabbytes":  "[string] -> bytes",
+		"writebytes": "[string, bytes] -> unit",
+		"some":       "[T] -> option<T>",
+		"unwrap":     "[option<T>] -> T",
+		"default":    "[option<T>, T] -> T",
+	} {
+		sig, err := parseSignature(text, nil)
+		if err != nil {
+			panic(fmt.Sprintf("invalid signature for builtin '%s': %s", name, err.Error()))
+		}
+		prelude.vars[name] = scheme{t: sig, quantified: nil, generic: true}
+	}
+	prelude.vars["none"] = scheme{t: Option(Con{Name: "T", Rigid: true}), quantified: nil, generic: true}
+
+	// option is a union like any other, apart from its type taking the type of the value it holds
+	c := &Checker{errs: []error{}, level: 0, nextID: 0, global: newScope(prelude), records: map[string][]string{}, unions: map[string][]string{}, variants: map[string]variant{}}
+	c.unions["option"] = []string{"some", "none"}
+	c.variants["some"] = variant{union: "option", fields: []string{"value"}}
+	c.variants["none"] = variant{union: "option", fields: []string{}}
+
+	// error is a record like any other, but its values are only made by catching errors
+	c.records["error"] = []string{"message", "kind", "line", "column"}
+	return c
+}
+
+// Check checks the statements of a program, and returns every problem found
+func (c *Checker) Check(program []nodes.Node) []error {
+	c.errs = []error{}
+
+	// types and functions are known up front, so they can be used before they're declared
+	for _, n := range program {
+		switch n := n.(type) {
+		case nodes.TypeDecl:
+			c.declareRecord(n, c.global)
+		case nodes.UnionDecl:
+			c.declareUnion(n, c.global)
+		}
+	}
+	// every other top-level function is bound to a type variable, until the group of functions it belongs to is inferred
+	for _, n := range program {
+		if fd, ok := n.(nodes.FunDecl); ok {
+			if sig, ok, err := signature(fd, c.records); ok {
+				c.global.vars[fd.Name] = scheme{t: sig, quantified: nil, generic: true}
+			} else {
+				if err != nil {
+					c.errs = append(c.errs, err)
+				}
+				c.global.vars[fd.Name] = mono(c.fresh())
+			}
+		}
+	}
+
+	groups := c.groups(program)
+	for _, n := range program {
+		if fd, ok := n.(nodes.FunDecl); ok {
+			if g, ok := groups[fd.Name]; ok {
+				c.inferGroup(g)
+				continue
+			}
+		}
+		c.infer(n, c.global)
+	}
+
+	return c.errs
+}
+
+// TypeOf infers the type of a single node without keeping anything it defines
+func (c *Checker) TypeOf(n nodes.Node) (Type, []error) {
+	c.errs = []error{}
+	t := c.infer(n, newScope(c.global))
+	return t, c.errs
+}
+
+// errorf reports an error at tok; any types among a are shown with the names of their unknown variables made consistent
+func (c *Checker) errorf(tok tokens.Token, code string, format string, a ...any) {
+	c.report(tok.Err(code, format, showAll(a)...))
+}
+
+func (c *Checker) report(d diagnostics.Diagnostic) {
+	c.errs = append(c.errs, d)
+}
+
+func (c *Checker) fresh() Type {
+	c.nextID++
+	return &Var{id: c.nextID - 1, level: c.level, ref: nil}
+}
+
+func (c *Checker) instantiate(sch scheme) Type {
+	vars := map[*Var]Type{}
+	for _, v := range sch.quantified {
+		vars[v] = c.fresh()
+	}
+
+	var generics map[string]Type
+	if sch.generic {
+		generics = map[string]Type{}
+	}
+
+	return instantiate(sch.t, vars, generics, c.fresh)
+}
+
+// generalize quantifies the variables in t that were introduced by a function declaration deeper than the current one
+func (c *Checker) generalize(t Type) scheme {
+	quantified := []*Var{}
+	seen := map[*Var]bool{}
+
+	var collect func(t Type)
+	collect = func(t Type) {
+		switch t := prune(t).(type) {
+		case *Var:
+			if t.level > c.level && !seen[t] {
+				seen[t] = true
+				quantified = append(quantified, t)
+			}
+		case Con:
+			for _, a := range t.Args {
+				collect(a)
+			}
+		case Fun:
+			for _, p := range t.Params {
+				collect(p)
+			}
+			collect(t.Ret)
+		}
+	}
+	collect(t)
+
+	return scheme{t: t, quantified: quantified, generic: false}
+}
+
+// signature finds and parses the signature in a function's doc comments, if it has one
+func signature(fd nodes.FunDecl, records map[string][]string) (Fun, bool, error) {
+	for _, doc := range fd.Doc {
+		if !isSignature(doc.GetLit()) {
+			continue
+		}
+
+		sig, err := parseSignature(doc.GetLit(), records)
+		if err != nil {
+			return Fun{}, false, doc.Err(diagnostics.InvalidSignature, "invalid signature for '%s': %s", fd.Name, err.Error())
+		} else if len(sig.Params) != fd.Arity {
+			return Fun{}, false, doc.Err(diagnostics.InvalidSignature, "the signature of '%s' has %d parameter(s), but the function takes %d", fd.Name, len(sig.Params), fd.Arity)
+		}
+		return sig, true, nil
+	}
+	return Fun{}, false, nil
+}
+
+func (c *Checker) infer(n nodes.Node, s *scope) Type {
+	switch n := n.(type) {
+	case nodes.Number:
+		return Number
+	case nodes.String:
+		return String
+	case nodes.Bytes:
+		return Bytes
+	case nodes.Char:
+		return Char
+	case nodes.Bool:
+		return Boolean
+	case nodes.Ident:
+		sch, ok := s.lookup(n.Tok.GetLit())
+		if !ok {
+			c.errorf(n.Tok, diagnostics.Undefined, "'%s' is not defined", n.Tok.GetLit())
+			return Any{}
+		}
+		return c.instantiate(sch)
+	case nodes.Arg:
+		f := s.nearestFrame()
+		if f == nil || n.Index > len(f.params) {
+			return Any{}
+		}
+		return f.params[n.Index-1]
+	case nodes.List:
+		elem := c.fresh()
+		for _, e := range n.Elems {
+			if err := unify(elem, c.infer(e, s)); err != nil {
+				elem = Any{}
+			}
+		}
+		return List(elem)
+	case nodes.Tuple:
+		return Tuple(c.inferAll(n.Elems, s)...)
+	case nodes.Vector:
+		elem := c.fresh()
+		for _, e := range n.Elems {
+			if err := unify(elem, c.infer(e, s)); err != nil {
+				elem = Any{}
+			}
+		}
+		return Vector(elem)
+	case nodes.Set:
+		elem := c.fresh()
+		for _, e := range n.Elems {
+			if err := unify(elem, c.infer(e, s)); err != nil {
+				elem = Any{}
+			}
+		}
+		return Set(elem)
+	case nodes.Map:
+		key, value := c.fresh(), c.fresh()
+		for i := range n.Keys {
+			if err := unify(key, c.infer(n.Keys[i], s)); err != nil {
+				key = Any{}
+			}
+			if err := unify(value, c.infer(n.Values[i], s)); err != nil {
+				value = Any{}
+			}
+		}
+		return Map(key, value)
+	case nodes.Call:
+		callee := c.infer(nodes.Ident{Tok: n.Tok}, s)
+		return c.apply(n.Tok, callee, c.inferAll(n.Args, s))
+	case nodes.SelfCall:
+		f := s.nearestFrame()
+		if f == nil {
+			return Any{}
+		}
+		return c.apply(n.Tok, f.self, c.inferAll(n.Args, s))
+	case nodes.Index:
+		return c.inferIndex(n, s)
+	case nodes.Unary:
+		return c.inferUnary(n, s)
+	case nodes.Binary:
+		return c.inferBinary(n, s)
+	case nodes.Conditional:
+		c.infer(n.Cond, s)
+		then := c.infer(n.Then, s)
+		els := c.infer(n.Else, s)
+		if err := unify(then, els); err != nil {
+			c.errorf(n.Tok, diagnostics.TypeMismatch, "the branches of this conditional have different types, %s and %s", then, els)
+			return Any{}
+		}
+		return then
+	case nodes.Pipe:
+		value := c.infer(n.Value, s)
+		switch target := n.Target.(type) {
+		case nodes.Call:
+			callee := c.infer(nodes.Ident{Tok: target.Tok}, s)
+			return c.apply(target.Tok, callee, append([]Type{value}, c.inferAll(target.Args, s)...))
+		case nodes.SelfCall:
+			f := s.nearestFrame()
+			if f == nil {
+				return Any{}
+			}
+			return c.apply(target.Tok, f.self, append([]Type{value}, c.inferAll(target.Args, s)...))
+		case nodes.Ident:
+			return c.apply(target.Tok, c.infer(target, s), []Type{value})
+		}
+		return Any{}
+	case nodes.FunDecl:
+		return c.inferFun(n, s)
+	case nodes.TypeDecl:
+		return c.declareRecord(n, s)
+	case nodes.UnionDecl:
+		return c.declareUnion(n, s)
+	case nodes.Match:
+		return c.inferMatch(n, s)
+	case nodes.Try:
+		body := c.infer(n.Body, s)
+		inner := newScope(s)
+		c.inferPattern(n.Pattern, Con{Name: "error"}, inner)
+		handler := c.infer(n.Handler, inner)
+		if err := unify(body, handler); err != nil {
+			c.errorf(n.Tok, diagnostics.TypeMismatch, "the body and handler of this try have different types, %s and %s", body, handler)
+			return Any{}
+		}
+		return body
+	case nodes.Field:
+		return c.inferField(n, s)
+	case nodes.MyDecl:
+		t := c.infer(n.Value, s)
+		if n.Pattern != nil {
+			c.inferPattern(n.Pattern, t, s)
+			return t
+		}
+		s.vars[n.Name] = mono(t)
+		return t
+	case nodes.Block:
+		inner := newScope(s)
+		var t Type = Any{}
+		for _, stmt := range n.Body {
+			t = c.infer(stmt, inner)
+		}
+		return t
+	}
+
+	return Any{}
+}
+
+// declareRecord learns a record type, and defines its constructor; fields can hold values of any type
+func (c *Checker) declareRecord(n nodes.TypeDecl, s *scope) Type {
+	fields := []string{}
+	params := []Type{}
+	for _, f := range n.Fields {
+		fields = append(fields, f.GetLit())
+		params = append(params, Any{})
+	}
+	c.records[n.Name] = fields
+
+	constructor := Fun{Params: params, Ret: Con{Name: n.Name}}
+	s.vars[n.Name] = mono(constructor)
+	return constructor
+}
+
+// declareUnion learns a tagged union, and defines its variants; variants with fields are constructors, the rest are values
+func (c *Checker) declareUnion(n nodes.UnionDecl, s *scope) Type {
+	union := Con{Name: n.Name}
+	names := []string{}
+	all := []string{}
+
+	for _, v := range n.Variants {
+		name := v.Tok.GetLit()
+		names = append(names, name)
+
+		fields := []string{}
+		params := []Type{}
+		for _, f := range v.Fields {
+			fields = append(fields, f.GetLit())
+			params = append(params, Any{})
+			if !slices.Contains(all, f.GetLit()) {
+				all = append(all, f.GetLit())
+			}
+		}
+		c.variants[name] = variant{union: n.Name, fields: fields}
+
+		if len(params) == 0 {
+			s.vars[name] = mono(union)
+		} else {
+			s.vars[name] = mono(Fun{Params: params, Ret: union})
+		}
+	}
+
+	c.records[n.Name] = all
+	c.unions[n.Name] = names
+	return union
+}
+
+func (c *Checker) inferMatch(n nodes.Match, s *scope) Type {
+	value := c.infer(n.Value, s)
+
+	var result Type = c.fresh()
+	for _, arm := range n.Arms {
+		inner := newScope(s)
+		c.inferPattern(arm.Pattern, value, inner)
+		if arm.Guard != nil {
+			c.infer(arm.Guard, inner)
+		}
+
+		body := c.infer(arm.Body, inner)
+		if err := unify(result, body); err != nil {
+			c.errorf(n.Tok, diagnostics.TypeMismatch, "the arms of this match have different types, %s and %s", result, body)
+			result = Any{}
+		}
+	}
+
+	c.checkExhaustive(n, s)
+	return result
+}
+
+// inferPattern unifies a pattern with the type of the value it's matched against, and defines the names it binds in s
+func (c *Checker) inferPattern(p nodes.Pattern, t Type, s *scope) {
+	var tok tokens.Token
+	var matched Type
+
+	switch p := p.(type) {
+	case nodes.WildcardPattern:
+		return
+	case nodes.NamePattern:
+		if _, ok := c.nullaryVariant(p.Tok.GetLit(), s); ok {
+			tok = p.Tok
+			matched, _ = c.variantType(c.variants[p.Tok.GetLit()])
+			break
+		}
+		s.vars[p.Tok.GetLit()] = mono(t)
+		return
+	case nodes.LiteralPattern:
+		tok, matched = p.Tok, c.infer(p.Value, s)
+	case nodes.ConstructorPattern:
+		tok = p.Tok
+		name := p.Tok.GetLit()
+		fields, ok := c.constructorFields(name)
+		fieldTypes := []Type{}
+		if v, isVariant := c.variants[name]; isVariant {
+			matched, fieldTypes = c.variantType(v)
+		} else if ok {
+			matched = Con{Name: name}
+		} else {
+			c.errorf(p.Tok, diagnostics.Undefined, "'%s' is not a record type or a variant", name)
+			matched = Any{}
+		}
+
+		if ok && len(fields) != len(p.Args) {
+			c.errorf(p.Tok, diagnostics.ArityMismatch, "'%s' has %d field(s), but the pattern has %d", name, len(fields), len(p.Args))
+		}
+		for i, arg := range p.Args {
+			var field Type = Any{}
+			if i < len(fieldTypes) {
+				field = fieldTypes[i]
+			}
+			c.inferPattern(arg, field, s)
+		}
+	case nodes.TuplePattern:
+		elems := []Type{}
+		for range p.Elems {
+			elems = append(elems, c.fresh())
+		}
+		tok, matched = p.Tok, Tuple(elems...)
+		for i, e := range p.Elems {
+			c.inferPattern(e, elems[i], s)
+		}
+	case nodes.ListPattern:
+		elem := c.fresh()
+		tok, matched = p.Tok, List(elem)
+		for _, e := range p.Elems {
+			c.inferPattern(e, elem, s)
+		}
+		if p.Rest != nil {
+			c.inferPattern(p.Rest, matched, s)
+		}
+	default:
+		return
+	}
+
+	if err := unify(t, matched); err != nil {
+		c.errorf(tok, diagnostics.TypeMismatch, "this pattern matches %s, but the value is %s", matched, t)
+	}
+}
+
+// variantType returns the type of the union a variant belongs to, and the types of its fields, which are only known for options
+func (c *Checker) variantType(v variant) (Type, []Type) {
+	if v.union == "option" {
+		elem := c.fresh()
+		return Option(elem), []Type{elem}
+	}
+
+	fields := []Type{}
+	for range v.fields {
+		fields = append(fields, Any{})
+	}
+	return Con{Name: v.union}, fields
+}
+
+// nullaryVariant returns the union of the variant without fields that name refers to, if it refers to one
+func (c *Checker) nullaryVariant(name string, s *scope) (string, bool) {
+	v, ok := c.variants[name]
+	if !ok || len(v.fields) != 0 {
+		return "", false
+	}
+
+	sch, ok := s.lookup(name)
+	if con, isCon := sch.t.(Con); !ok || !isCon || con.Name != v.union {
+		return "", false
+	}
+	return v.union, true
+}
+
+// constructorFields returns the fields of a record type or a variant, which can both be matched by `name [patterns]`
+func (c *Checker) constructorFields(name string) ([]string, bool) {
+	if v, ok := c.variants[name]; ok {
+		return v.fields, true
+	} else if _, ok := c.unions[name]; ok {
+		return nil, false
+	}
+
+	fields, ok := c.records[name]
+	return fields, ok
+}
+
+// inferIndex infers `value [i]`, whose value has to be a list, string, vector or bytes
+func (c *Checker) inferIndex(n nodes.Index, s *scope) Type {
+	value := c.infer(n.Value, s)
+	i := c.infer(n.Index, s)
+	if err := unify(Number, i); err != nil {
+		c.errorf(n.Tok, diagnostics.TypeMismatch, "an index must be a number, but was given %s", i)
+	}
+
+	con, ok := prune(value).(Con)
+	if !ok {
+		return Any{}
+	} else if elem, ok := elemType(con); ok {
+		return elem
+	}
+	c.errorf(n.Tok, diagnostics.TypeMismatch, "type %s cannot be indexed", value)
+	return Any{}
+}
+
+func (c *Checker) inferField(n nodes.Field, s *scope) Type {
+	record := c.infer(n.Record, s)
+
+	con, ok := prune(record).(Con)
+	if !ok {
+		return Any{}
+	}
+
+	fields, ok := c.records[con.Name]
+	if !ok || con.Rigid {
+		c.errorf(n.Tok, diagnostics.TypeMismatch, "type %s has no fields", record)
+	} else if !slices.Contains(fields, n.Name) {
+		c.errorf(n.Tok, diagnostics.TypeMismatch, "type %s has no field '%s'", con.Name, n.Name)
+	}
+	return Any{}
+}
+
+func (c *Checker) inferAll(ns []nodes.Node, s *scope) []Type {
+	types := []Type{}
+	for _, n := range ns {
+		types = append(types, c.infer(n, s))
+	}
+	return types
+}
+
+func (c *Checker) inferFun(n nodes.FunDecl, s *scope) Type {
+	sig, hasSig, err := signature(n, c.records)
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+
+	if !hasSig {
+		return c.inferFuns([]nodes.FunDecl{n}, s)[0]
+	}
+
+	c.level++
+	c.inferBody(n, sig, true, s)
+	c.level--
+
+	s.vars[n.Name] = scheme{t: sig, quantified: nil, generic: true}
+	return sig
+}
+
+// inferFuns infers functions without signatures that may call each other, and generalizes them once all of their bodies have been inferred
+func (c *Checker) inferFuns(decls []nodes.FunDecl, s *scope) []Type {
+	c.level++
+
+	selves := []Fun{}
+	for _, n := range decls {
+		params := []Type{}
+		for range n.Arity {
+			params = append(params, c.fresh())
+		}
+		self := Fun{Params: params, Ret: c.fresh()}
+		selves = append(selves, self)
+		s.vars[n.Name] = mono(self)
+	}
+
+	for i, n := range decls {
+		c.inferBody(n, selves[i], false, s)
+	}
+
+	c.level--
+
+	types := []Type{}
+	for i, n := range decls {
+		s.vars[n.Name] = c.generalize(selves[i])
+		types = append(types, selves[i])
+	}
+	return types
+}
+
+// inferBody infers the body of a function whose type is self, with its parameters bound in a scope of their own
+func (c *Checker) inferBody(n nodes.FunDecl, self Fun, hasSig bool, s *scope) {
+	body := newScope(s)
+	body.frame = &frame{params: self.Params, self: self}
+	body.vars[n.Name] = mono(self)
+	for i, p := range n.Params {
+		if n.Patterns != nil && n.Patterns[i] != nil {
+			c.inferPattern(n.Patterns[i], self.Params[i], body)
+		} else {
+			body.vars[p.GetLit()] = mono(self.Params[i])
+		}
+	}
+
+	ret := c.infer(n.Body, body)
+	if err := unify(self.Ret, ret); err != nil {
+		d := n.Tok.Err(diagnostics.TypeMismatch, "function '%s' should return %s, but returns %s", showAll([]any{n.Name, self.Ret, ret})...)
+		if hasSig {
+			d = d.WithNote("the signature of '%s' is %s", n.Name, self.Str())
+		}
+		c.report(d)
+	}
+}
+
+func (c *Checker) apply(tok tokens.Token, callee Type, args []Type) Type {
+	switch f := prune(callee).(type) {
+	case Builtin:
+		return c.applyBuiltin(tok, f.Name, args)
+	case Fun:
+		if len(f.Params) != len(args) {
+			c.errorf(tok, diagnostics.ArityMismatch, "function '%s' expects %d argument(s), but received %d", tok.GetLit(), len(f.Params), len(args))
+			return Any{}
+		}
+		for i := range args {
+			if err := unify(f.Params[i], args[i]); err != nil {
+				c.report(tok.Err(diagnostics.TypeMismatch, "argument %d of '%s' should be %s, but is %s", showAll([]any{i + 1, tok.GetLit(), f.Params[i], args[i]})...).
+					WithNote("'%s' has the type %s", tok.GetLit(), Show(f)))
+			}
+		}
+		return f.Ret
+	case *Var:
+		ret := c.fresh()
+		if err := unify(f, Fun{Params: args, Ret: ret}); err != nil {
+			c.errorf(tok, diagnostics.TypeMismatch, "'%s' cannot be called with these arguments: %s", tok.GetLit(), err.Error())
+			return Any{}
+		}
+		return ret
+	case Any:
+		return Any{}
+	case Con:
+		// calling a list, string or vector indexes it
+		if elem, ok := elemType(f); ok && len(args) == 1 {
+			if err := unify(Number, args[0]); err != nil {
+				c.errorf(tok, diagnostics.TypeMismatch, "'%s' must be indexed by a number, but was given %s", tok.GetLit(), args[0])
+			}
+			return elem
+		}
+	}
+
+	c.errorf(tok, diagnostics.TypeMismatch, "'%s' is of type '%s', which cannot be called", tok.GetLit(), callee)
+	return Any{}
+}
+
+func (c *Checker) applyBuiltin(tok tokens.Token, name string, args []Type) Type {
+	switch name {
+	case "say", "print":
+		return Tuple()
+	case "raise":
+		return c.applyRaise(tok, args)
+	case "has":
+		return c.applyHas(tok, args)
+	case "slice":
+		return c.applySlice(tok, args)
+	case "at":
+		return c.applyAt(tok, args)
+	case "range", "cycle", "set", "bytes", "map", "filter", "find", "take":
+		return c.applySeq(tok, name, args)
+	}
+
+	if len(args) != 1 {
+		c.errorf(tok, diagnostics.ArityMismatch, "function '%s' expects 1 argument(s), but received %d", name, len(args))
+		return Any{}
+	}
+
+	switch arg := prune(args[0]).(type) {
+	case *Var, Any:
+		if name == "len" {
+			return Number
+		}
+		return Any{}
+	case Con:
+		if arg.Name == "string" {
+			switch name {
+			case "len":
+				return Number
+			case "head":
+				return Option(Char)
+			case "tail":
+				return String
+			}
+		} else if arg.Name == "bytes" {
+			switch name {
+			case "len":
+				return Number
+			case "head":
+				return Option(Number)
+			case "tail":
+				return Bytes
+			}
+		} else if (arg.Name == "map" || arg.Name == "set") && name == "len" {
+			return Number
+		} else if arg.Name == "list" || arg.Name == "vector" || arg.Name == "sequence" {
+			switch name {
+			case "len":
+				return Number
+			case "head":
+				return Option(arg.Args[0])
+			case "tail":
+				return arg
+			}
+		}
+	}
+
+	c.errorf(tok, diagnostics.TypeMismatch, "'%s' expects a list, vector, string, bytes or sequence, but was given %s", name, args[0])
+	return Any{}
+}
+
+// applySlice types `slice`, which slices vectors and bytes
+func (c *Checker) applySlice(tok tokens.Token, args []Type) Type {
+	if len(args) != 3 {
+		c.errorf(tok, diagnostics.ArityMismatch, "function 'slice' expects 3 argument(s), but received %d", len(args))
+		return Any{}
+	}
+	for _, a := range args[1:] {
+		if err := unify(Number, a); err != nil {
+			c.errorf(tok, diagnostics.TypeMismatch, "'slice' expects integer indices, but was given %s", a)
+		}
+	}
+
+	switch arg := prune(args[0]).(type) {
+	case *Var, Any:
+		return Any{}
+	case Con:
+		if arg.Name == "vector" || arg.Name == "bytes" {
+			return arg
+		}
+	}
+	c.errorf(tok, diagnostics.TypeMismatch, "'slice' expects a vector or bytes, but was given %s", args[0])
+	return Any{}
+}
+
+// applyRaise types `raise`, which never returns, so what it would return can be any type at all
+func (c *Checker) applyRaise(tok tokens.Token, args []Type) Type {
+	if len(args) < 1 || len(args) > 2 {
+		c.errorf(tok, diagnostics.ArityMismatch, "function 'raise' expects 1 or 2 argument(s), but received %d", len(args))
+		return c.fresh()
+	}
+
+	if con, ok := prune(args[0]).(Con); ok && con.Name == "error" && len(args) == 1 {
+		return c.fresh()
+	} else if err := unify(String, args[0]); err != nil {
+		c.errorf(tok, diagnostics.TypeMismatch, "'raise' expects a message or an error, but was given %s", args[0])
+	}
+	if len(args) == 2 {
+		if err := unify(String, args[1]); err != nil {
+			c.errorf(tok, diagnostics.TypeMismatch, "'raise' expects the kind of error to be a string, but was given %s", args[1])
+		}
+	}
+	return c.fresh()
+}
+
+// applyHas types `has`, which looks for a key in a map, or an element in a set
+func (c *Checker) applyHas(tok tokens.Token, args []Type) Type {
+	if len(args) != 2 {
+		c.errorf(tok, diagnostics.ArityMismatch, "function 'has' expects 2 argument(s), but received %d", len(args))
+		return Boolean
+	}
+
+	switch arg := prune(args[0]).(type) {
+	case *Var, Any:
+	case Con:
+		if arg.Name != "map" && arg.Name != "set" {
+			c.errorf(tok, diagnostics.TypeMismatch, "'has' expects a map or a set, but was given %s", args[0])
+		} else if err := unify(arg.Args[0], args[1]); err != nil {
+			c.errorf(tok, diagnostics.TypeMismatch, "'has' is looking for %s in %s", args[1], arg)
+		}
+	default:
+		c.errorf(tok, diagnostics.TypeMismatch, "'has' expects a map or a set, but was given %s", args[0])
+	}
+	return Boolean
+}
+
+// applySeq types the sequence builtins, which take lists, vectors, strings and sequences alike
+func (c *Checker) applySeq(tok tokens.Token, name string, args []Type) Type {
+	if name == "range" {
+		if len(args) < 1 || len(args) > 3 {
+			c.errorf(tok, diagnostics.ArityMismatch, "function 'range' expects 1 to 3 argument(s), but received %d", len(args))
+		}
+		for _, a := range args {
+			if err := unify(Number, a); err != nil {
+				c.errorf(tok, diagnostics.TypeMismatch, "'range' expects numbers, but was given %s", a)
+			}
+		}
+		return Seq(Number)
+	}
+
+	arity := 2
+	if name == "cycle" || name == "set" || name == "bytes" {
+		arity = 1
+	}
+	if name == "bytes" && len(args) == 1 {
+		// a string's bytes are its UTF-8 encoding, rather than its characters
+		if con, ok := prune(args[0]).(Con); ok && (con.Name == "string" || con.Name == "bytes") {
+			return Bytes
+		}
+	}
+	if len(args) != arity {
+		c.errorf(tok, diagnostics.ArityMismatch, "function '%s' expects %d argument(s), but received %d", name, arity, len(args))
+		return Any{}
+	}
+
+	var elem Type = Any{}
+	switch arg := prune(args[0]).(type) {
+	case *Var, Any:
+	case Con:
+		item, ok := itemType(arg)
+		if !ok {
+			c.errorf(tok, diagnostics.TypeMismatch, "'%s' expects a list, vector, string, set, bytes or sequence, but was given %s", name, args[0])
+			return Any{}
+		}
+		elem = item
+	default:
+		c.errorf(tok, diagnostics.TypeMismatch, "'%s' expects a list, vector, string, set, bytes or sequence, but was given %s", name, args[0])
+		return Any{}
+	}
+
+	switch name {
+	case "cycle":
+		return Seq(elem)
+	case "set":
+		return Set(elem)
+	case "bytes":
+		if err := unify(Number, elem); err != nil {
+			c.errorf(tok, diagnostics.TypeMismatch, "'bytes' expects numbers from 0 to 255, but was given %s", args[0])
+		}
+		return Bytes
+	case "take":
+		if err := unify(Number, args[1]); err != nil {
+			c.errorf(tok, diagnostics.TypeMismatch, "'take' expects a number of elements, but was given %s", args[1])
+		}
+		return List(elem)
+	case "map":
+		ret := c.fresh()
+		if err := unify(Fun{Params: []Type{elem}, Ret: ret}, args[1]); err != nil {
+			c.errorf(tok, diagnostics.TypeMismatch, "'map' expects a function of %s, but was given %s", elem, args[1])
+			return Seq(Any{})
+		}
+		return Seq(ret)
+	default:
+		if err := unify(Fun{Params: []Type{elem}, Ret: c.fresh()}, args[1]); err != nil {
+			c.errorf(tok, diagnostics.TypeMismatch, "'%s' expects a function of %s, but was given %s", name, elem, args[1])
+		}
+		if name == "find" {
+			return Option(elem)
+		}
+		return Seq(elem)
+	}
+}
+
+// applyAt types `at [xs, i]`, which indexes a list, vector or string
+func (c *Checker) applyAt(tok tokens.Token, args []Type) Type {
+	if len(args) != 2 {
+		c.errorf(tok, diagnostics.ArityMismatch, "function 'at' expects 2 argument(s), but received %d", len(args))
+		return Any{}
+	} else if err := unify(Number, args[1]); err != nil {
+		c.errorf(tok, diagnostics.TypeMismatch, "'at' expects a number index, but was given %s", args[1])
+	}
+
+	switch arg := prune(args[0]).(type) {
+	case *Var, Any:
+		return Any{}
+	case Con:
+		if elem, ok := elemType(arg); ok {
+			return elem
+		}
+	}
+
+	c.errorf(tok, diagnostics.TypeMismatch, "'at' expects a list, vector or string, but was given %s", args[0])
+	return Any{}
+}
+
+// sequence returns t if it's a list or a vector, which the arithmetic operators work through element-wise
+func sequence(t Type) (Con, bool) {
+	con, ok := prune(t).(Con)
+	return con, ok && (con.Name == "list" || con.Name == "vector")
+}
+
+func (c *Checker) inferUnary(n nodes.Unary, s *scope) Type {
+	operand := c.infer(n.Operand, s)
+
+	if n.Op.IsKind(tokens.Not) {
+		return Boolean
+	}
+
+	if con, ok := sequence(operand); ok {
+		return con
+	} else if err := unify(Number, operand); err != nil {
+		c.errorf(n.Op, diagnostics.TypeMismatch, "operator '%s' expects a number, but found %s", n.Op.GetLit(), operand)
+		return Any{}
+	}
+	return Number
+}
+
+func (c *Checker) inferBinary(n nodes.Binary, s *scope) Type {
+	left := c.infer(n.Left, s)
+	right := c.infer(n.Right, s)
+
+	switch n.Op.GetKind() {
+	case tokens.And, tokens.Or, tokens.Equals, tokens.NotEquals:
+		return Boolean
+	case tokens.GreaterThan, tokens.LesserThan, tokens.GreaterThanOrEqualTo, tokens.LesserThanOrEqualTo:
+		if err := unify(left, right); err != nil {
+			c.errorf(n.Op, diagnostics.TypeMismatch, "cannot compare %s with %s", left, right)
+		}
+		return Boolean
+	case tokens.Concat:
+		switch l := prune(left).(type) {
+		case Con:
+			if l.Name == "string" {
+				if err := unify(String, right); err != nil {
+					c.errorf(n.Op, diagnostics.TypeMismatch, "cannot concatenate string with %s", right)
+				}
+				return String
+			} else if l.Name == "bytes" {
+				// bytes can have bytes or a single byte added to them
+				if r, ok := prune(right).(Con); !ok || r.Name != "bytes" {
+					if err := unify(Number, right); err != nil {
+						c.errorf(n.Op, diagnostics.TypeMismatch, "cannot concatenate bytes with %s", right)
+					}
+				}
+				return Bytes
+			} else if _, ok := sequence(l); ok {
+				if r, ok := prune(right).(Con); ok && r.Name == l.Name {
+					if err := unify(l, r); err != nil {
+						c.errorf(n.Op, diagnostics.TypeMismatch, "cannot concatenate %s with %s", l, r)
+					}
+				} else if err := unify(l.Args[0], right); err != nil {
+					c.errorf(n.Op, diagnostics.TypeMismatch, "cannot append %s to %s", right, l)
+				}
+				return l
+			}
+		case *Var, Any:
+			return Any{}
+		}
+		c.errorf(n.Op, diagnostics.TypeMismatch, "operator '++' expects a string, list, vector or bytes, but found %s", left)
+		return Any{}
+	}
+
+	// `|`, `&`, `^` and `-` are set operations when there's a set on the left
+	if l, ok := prune(left).(Con); ok && l.Name == "set" {
+		switch n.Op.GetKind() {
+		case tokens.BitOr, tokens.BitAnd, tokens.BitXOR, tokens.Hyphen:
+			if err := unify(l, right); err != nil {
+				c.errorf(n.Op, diagnostics.TypeMismatch, "operator '%s' expects two sets, but found %s and %s", n.Op.GetLit(), l, right)
+				return Any{}
+			}
+			return l
+		}
+	}
+
+	// the arithmetic operators also work element-wise on lists and vectors
+	if l, ok := sequence(left); ok {
+		return l
+	} else if r, ok := sequence(right); ok {
+		return r
+	}
+
+	for _, operand := range []Type{left, right} {
+		if err := unify(Number, operand); err != nil {
+			c.errorf(n.Op, diagnostics.TypeMismatch, "operator '%s' expects numbers, but found %s", n.Op.GetLit(), operand)
+			return Any{}
+		}
+	}
+	return Number
+}
@@ -0,0 +1,231 @@
+package checker
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/diagnostics"
+	"github.com/voidwyrm-2/opal/parser/nodes"
+)
+
+type spaceKind int
+
+const (
+	wildSpace spaceKind = iota
+	variantSpace
+	recordSpace
+	boolSpace
+	nilSpace
+	consSpace
+	literalSpace
+	tupleSpace
+)
+
+/*
+space is a pattern reduced to the shape of the values it matches, which is all that exhaustiveness checking needs.
+List patterns become chains of cons cells ending in nil, or in the rest pattern if there is one,
+so `[x | xs]` is cons(x, xs) and `[a, b]` is cons(a, cons(b, nil)).
+*/
+type space struct {
+	kind spaceKind
+	name string
+	args []space
+}
+
+var wild = space{kind: wildSpace, name: "_", args: nil}
+
+func wilds(n int) []space {
+	spaces := []space{}
+	for range n {
+		spaces = append(spaces, wild)
+	}
+	return spaces
+}
+
+func (c *Checker) toSpace(p nodes.Pattern, s *scope) space {
+	switch p := p.(type) {
+	case nodes.NamePattern:
+		if _, ok := c.nullaryVariant(p.Tok.GetLit(), s); ok {
+			return space{kind: variantSpace, name: p.Tok.GetLit(), args: nil}
+		}
+	case nodes.LiteralPattern:
+		if b, ok := p.Value.(nodes.Bool); ok {
+			return space{kind: boolSpace, name: b.Str(), args: nil}
+		}
+		return space{kind: literalSpace, name: p.Str(), args: nil}
+	case nodes.ConstructorPattern:
+		fields, ok := c.constructorFields(p.Tok.GetLit())
+		if !ok || len(fields) != len(p.Args) {
+			break
+		}
+
+		kind := recordSpace
+		if _, ok := c.variants[p.Tok.GetLit()]; ok {
+			kind = variantSpace
+		}
+
+		args := []space{}
+		for _, a := range p.Args {
+			args = append(args, c.toSpace(a, s))
+		}
+		return space{kind: kind, name: p.Tok.GetLit(), args: args}
+	case nodes.TuplePattern:
+		elems := []space{}
+		for _, e := range p.Elems {
+			elems = append(elems, c.toSpace(e, s))
+		}
+		// tuples of different lengths are different types, so they're told apart by name
+		return space{kind: tupleSpace, name: "(" + strconv.Itoa(len(elems)) + ")", args: elems}
+	case nodes.ListPattern:
+		list := space{kind: nilSpace, name: "[]", args: nil}
+		if p.Rest != nil {
+			list = c.toSpace(p.Rest, s)
+		}
+		for i := len(p.Elems) - 1; i >= 0; i-- {
+			list = space{kind: consSpace, name: "|", args: []space{c.toSpace(p.Elems[i], s), list}}
+		}
+		return list
+	}
+
+	return wild
+}
+
+// siblings returns every constructor of the type sp belongs to, or false if there are too many to list
+func (c *Checker) siblings(sp space) ([]space, bool) {
+	switch sp.kind {
+	case variantSpace:
+		all := []space{}
+		for _, name := range c.unions[c.variants[sp.name].union] {
+			all = append(all, space{kind: variantSpace, name: name, args: wilds(len(c.variants[name].fields))})
+		}
+		return all, true
+	case recordSpace, tupleSpace:
+		return []space{{kind: sp.kind, name: sp.name, args: wilds(len(sp.args))}}, true
+	case boolSpace:
+		return []space{{kind: boolSpace, name: "True", args: nil}, {kind: boolSpace, name: "False", args: nil}}, true
+	case nilSpace, consSpace:
+		return []space{{kind: nilSpace, name: "[]", args: nil}, {kind: consSpace, name: "|", args: wilds(2)}}, true
+	}
+	return nil, false
+}
+
+// specialize keeps the rows that can match a value built by ctor, replacing their first space with its arguments
+func specialize(rows [][]space, ctor space) [][]space {
+	special := [][]space{}
+	for _, row := range rows {
+		first := row[0]
+		if first.kind == wildSpace {
+			special = append(special, append(wilds(len(ctor.args)), row[1:]...))
+		} else if first.kind == ctor.kind && first.name == ctor.name {
+			special = append(special, append(append([]space{}, first.args...), row[1:]...))
+		}
+	}
+	return special
+}
+
+/*
+missing looks for values of width columns that none of rows match, returning one as an example if there are any.
+It's the usefulness algorithm from Maranget's "Warnings for pattern matching":
+if the first column uses every constructor of its type, each is checked in turn,
+otherwise only the rows that match anything in the first column are left to cover the rest.
+*/
+func (c *Checker) missing(rows [][]space, width int) ([]space, bool) {
+	if width == 0 {
+		return []space{}, len(rows) == 0
+	}
+
+	used := map[string]bool{}
+	var ctors []space
+	complete := false
+	for _, row := range rows {
+		if row[0].kind == wildSpace || used[row[0].name] {
+			continue
+		}
+		used[row[0].name] = true
+		if ctors == nil {
+			var finite bool
+			ctors, finite = c.siblings(row[0])
+			complete = finite
+		}
+	}
+	for _, ctor := range ctors {
+		complete = complete && used[ctor.name]
+	}
+
+	if complete {
+		for _, ctor := range ctors {
+			if w, ok := c.missing(specialize(rows, ctor), len(ctor.args)+width-1); ok {
+				head := space{kind: ctor.kind, name: ctor.name, args: w[:len(ctor.args)]}
+				return append([]space{head}, w[len(ctor.args):]...), true
+			}
+		}
+		return nil, false
+	}
+
+	rest := [][]space{}
+	for _, row := range rows {
+		if row[0].kind == wildSpace {
+			rest = append(rest, row[1:])
+		}
+	}
+
+	w, ok := c.missing(rest, width-1)
+	if !ok {
+		return nil, false
+	}
+
+	// a constructor that isn't used makes a better example than `_`, when there is one
+	for _, ctor := range ctors {
+		if !used[ctor.name] {
+			return append([]space{ctor}, w...), true
+		}
+	}
+	return append([]space{wild}, w...), true
+}
+
+// checkExhaustive warns about a match that some values can get through without matching an arm; guarded arms might not match, so they don't count
+func (c *Checker) checkExhaustive(n nodes.Match, s *scope) {
+	rows := [][]space{}
+	for _, arm := range n.Arms {
+		if arm.Guard == nil {
+			rows = append(rows, []space{c.toSpace(arm.Pattern, s)})
+		}
+	}
+
+	if w, ok := c.missing(rows, 1); ok {
+		c.report(n.Tok.Warn(diagnostics.NonExhaustiveMatch, "this match is not exhaustive; for example, it doesn't match %s", w[0].Str()))
+	}
+}
+
+// Str formats a space the way it would be written as a pattern
+func (sp space) Str() string {
+	switch sp.kind {
+	case variantSpace, recordSpace:
+		if len(sp.args) == 0 && sp.kind == variantSpace {
+			return sp.name
+		}
+		args := []string{}
+		for _, a := range sp.args {
+			args = append(args, a.Str())
+		}
+		return sp.name + " [" + strings.Join(args, ", ") + "]"
+	case tupleSpace:
+		args := []string{}
+		for _, a := range sp.args {
+			args = append(args, a.Str())
+		}
+		return "(" + strings.Join(args, ", ") + ")"
+	case consSpace:
+		elems := []string{}
+		list := sp
+		for list.kind == consSpace {
+			elems = append(elems, list.args[0].Str())
+			list = list.args[1]
+		}
+		if list.kind == nilSpace {
+			return "[" + strings.Join(elems, ", ") + "]"
+		}
+		return "[" + strings.Join(elems, ", ") + " | " + list.Str() + "]"
+	}
+	return sp.name
+}
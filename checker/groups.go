@@ -0,0 +1,191 @@
+package checker
+
+import (
+	"slices"
+
+	"github.com/voidwyrm-2/opal/diagnostics"
+	"github.com/voidwyrm-2/opal/parser/nodes"
+)
+
+/*
+group is a set of top-level functions without signatures that call each other, directly or not.
+The functions of a group are inferred together, and only generalized once all of them have been,
+so that each one is used at a single type while the others are being worked out.
+*/
+type group struct {
+	decls []nodes.FunDecl
+	deps  []*group // the groups this one calls, which have to be inferred first
+	done  bool
+}
+
+// references adds the names that n uses to names; it doesn't account for shadowing, so it may find names that aren't really used
+func references(n nodes.Node, names map[string]bool) {
+	all := func(ns ...nodes.Node) {
+		for _, n := range ns {
+			if n != nil {
+				references(n, names)
+			}
+		}
+	}
+
+	switch n := n.(type) {
+	case nodes.Ident:
+		names[n.Tok.GetLit()] = true
+	case nodes.Call:
+		names[n.Tok.GetLit()] = true
+		all(n.Args...)
+	case nodes.SelfCall:
+		all(n.Args...)
+	case nodes.List:
+		all(n.Elems...)
+	case nodes.Tuple:
+		all(n.Elems...)
+	case nodes.Vector:
+		all(n.Elems...)
+	case nodes.Set:
+		all(n.Elems...)
+	case nodes.Map:
+		all(n.Keys...)
+		all(n.Values...)
+	case nodes.Index:
+		all(n.Value, n.Index)
+	case nodes.Unary:
+		all(n.Operand)
+	case nodes.Binary:
+		all(n.Left, n.Right)
+	case nodes.Conditional:
+		all(n.Then, n.Cond, n.Else)
+	case nodes.Pipe:
+		all(n.Value, n.Target)
+	case nodes.FunDecl:
+		all(n.Body)
+	case nodes.MyDecl:
+		all(n.Value)
+	case nodes.Field:
+		all(n.Record)
+	case nodes.Match:
+		all(n.Value)
+		for _, a := range n.Arms {
+			all(a.Guard, a.Body)
+		}
+	case nodes.Try:
+		all(n.Body, n.Handler)
+	case nodes.Block:
+		all(n.Body...)
+	}
+}
+
+/*
+groups splits the top-level functions of a program that don't have signatures into groups, keyed by the names of their functions.
+Functions with signatures aren't in any group, since their types are already known.
+*/
+func (c *Checker) groups(program []nodes.Node) map[string]*group {
+	decls := map[string]nodes.FunDecl{}
+	order := []string{}
+	for _, n := range program {
+		if fd, ok := n.(nodes.FunDecl); ok {
+			if _, ok, _ := signature(fd, c.records); !ok {
+				if _, seen := decls[fd.Name]; !seen {
+					order = append(order, fd.Name)
+				}
+				decls[fd.Name] = fd
+			}
+		}
+	}
+
+	calls := map[string][]string{}
+	for _, name := range order {
+		names := map[string]bool{}
+		references(decls[name].Body, names)
+		for _, other := range order {
+			if names[other] {
+				calls[name] = append(calls[name], other)
+			}
+		}
+	}
+
+	// the groups are the strongly connected components of the calls between the functions, found with Tarjan's algorithm
+	groupOf := map[string]*group{}
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+
+	var connect func(name string)
+	connect = func(name string) {
+		index[name] = len(index)
+		low[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, callee := range calls[name] {
+			if _, visited := index[callee]; !visited {
+				connect(callee)
+				low[name] = min(low[name], low[callee])
+			} else if onStack[callee] {
+				low[name] = min(low[name], index[callee])
+			}
+		}
+
+		if low[name] != index[name] {
+			return
+		}
+
+		g := &group{decls: []nodes.FunDecl{}, deps: []*group{}, done: false}
+		for {
+			member := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[member] = false
+			g.decls = append(g.decls, decls[member])
+			groupOf[member] = g
+			if member == name {
+				break
+			}
+		}
+		slices.Reverse(g.decls)
+	}
+
+	for _, name := range order {
+		if _, visited := index[name]; !visited {
+			connect(name)
+		}
+	}
+
+	// a group's callees in other groups were all finished before it was, so their groups are already known
+	for _, name := range order {
+		g := groupOf[name]
+		for _, callee := range calls[name] {
+			if dep := groupOf[callee]; dep != g {
+				g.deps = append(g.deps, dep)
+			}
+		}
+	}
+	return groupOf
+}
+
+// inferGroup infers a group of top-level functions, after the groups it calls
+func (c *Checker) inferGroup(g *group) {
+	if g.done {
+		return
+	}
+	g.done = true
+
+	for _, dep := range g.deps {
+		c.inferGroup(dep)
+	}
+
+	// uses of the functions that were checked before their group was are held to the types they turned out to have
+	bound := []Type{}
+	for _, fd := range g.decls {
+		bound = append(bound, c.global.vars[fd.Name].t)
+	}
+
+	c.inferFuns(g.decls, c.global)
+
+	for i, fd := range g.decls {
+		t := c.instantiate(c.global.vars[fd.Name])
+		if err := unify(bound[i], t); err != nil {
+			c.errorf(fd.Tok, diagnostics.TypeMismatch, "function '%s' is used as %s before it's declared, but has the type %s", fd.Name, bound[i], t)
+		}
+	}
+}
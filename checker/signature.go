@@ -0,0 +1,195 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+/*
+Signatures are written in `///` comments directly above a function:
+
+	/// [list<T>, number] -> T
+
+Parameter types go between the brackets and the return type follows the arrow.
+The known types are number, string, char, boolean, bytes, any, list<T>, vector<T>, sequence<T>, set<T>, option<T>, map<K, V>, (T, U) for tuples, () or unit and [params] -> return for functions;
+a plain `list`, `vector`, `sequence`, `set` or `map` holds anything, and any other name starting with an uppercase letter is a generic.
+Record types and tagged unions can be used by name once they're declared.
+*/
+type sigParser struct {
+	text    string
+	idx     int
+	records map[string][]string
+}
+
+func (sp *sigParser) skipSpace() {
+	for sp.idx < len(sp.text) && unicode.IsSpace(rune(sp.text[sp.idx])) {
+		sp.idx++
+	}
+}
+
+func (sp *sigParser) eat(s string) bool {
+	sp.skipSpace()
+	if len(sp.text)-sp.idx >= len(s) && sp.text[sp.idx:sp.idx+len(s)] == s {
+		sp.idx += len(s)
+		return true
+	}
+	return false
+}
+
+func (sp *sigParser) expect(s string) error {
+	if !sp.eat(s) {
+		return fmt.Errorf("expected '%s' at column %d of the signature", s, sp.idx+1)
+	}
+	return nil
+}
+
+func (sp *sigParser) name() string {
+	sp.skipSpace()
+	start := sp.idx
+	for sp.idx < len(sp.text) && (unicode.IsLetter(rune(sp.text[sp.idx])) || unicode.IsDigit(rune(sp.text[sp.idx])) || sp.text[sp.idx] == '_') {
+		sp.idx++
+	}
+	return sp.text[start:sp.idx]
+}
+
+func (sp *sigParser) parseFun() (Fun, error) {
+	if err := sp.expect("["); err != nil {
+		return Fun{}, err
+	}
+
+	params := []Type{}
+	if !sp.eat("]") {
+		for {
+			t, err := sp.parseType()
+			if err != nil {
+				return Fun{}, err
+			}
+			params = append(params, t)
+
+			if sp.eat("]") {
+				break
+			} else if err := sp.expect(","); err != nil {
+				return Fun{}, err
+			}
+		}
+	}
+
+	if err := sp.expect("->"); err != nil {
+		return Fun{}, err
+	}
+
+	ret, err := sp.parseType()
+	if err != nil {
+		return Fun{}, err
+	}
+
+	return Fun{Params: params, Ret: ret}, nil
+}
+
+// parseTuple parses the elements of a tuple type after its opening parenthesis; `(T,)` is a tuple of one element
+func (sp *sigParser) parseTuple() (Type, error) {
+	elems := []Type{}
+	for !sp.eat(")") {
+		t, err := sp.parseType()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, t)
+
+		if sp.eat(")") {
+			break
+		} else if err := sp.expect(","); err != nil {
+			return nil, err
+		}
+	}
+	return Tuple(elems...), nil
+}
+
+func (sp *sigParser) parseType() (Type, error) {
+	sp.skipSpace()
+	if sp.idx < len(sp.text) && sp.text[sp.idx] == '[' {
+		return sp.parseFun()
+	} else if sp.eat("(") {
+		return sp.parseTuple()
+	}
+
+	name := sp.name()
+	switch name {
+	case "":
+		return nil, fmt.Errorf("expected a type at column %d of the signature", sp.idx+1)
+	case "number":
+		return Number, nil
+	case "string":
+		return String, nil
+	case "char":
+		return Char, nil
+	case "boolean":
+		return Boolean, nil
+	case "bytes":
+		return Bytes, nil
+	case "any":
+		return Any{}, nil
+	case "unit":
+		return Tuple(), nil
+	case "list", "vector", "sequence", "set", "option":
+		if !sp.eat("<") {
+			return Con{Name: name, Args: []Type{Any{}}}, nil
+		}
+
+		elem, err := sp.parseType()
+		if err != nil {
+			return nil, err
+		} else if err := sp.expect(">"); err != nil {
+			return nil, err
+		}
+		return Con{Name: name, Args: []Type{elem}}, nil
+	case "map":
+		if !sp.eat("<") {
+			return Map(Any{}, Any{}), nil
+		}
+
+		key, err := sp.parseType()
+		if err != nil {
+			return nil, err
+		} else if err := sp.expect(","); err != nil {
+			return nil, err
+		}
+		value, err := sp.parseType()
+		if err != nil {
+			return nil, err
+		} else if err := sp.expect(">"); err != nil {
+			return nil, err
+		}
+		return Map(key, value), nil
+	}
+
+	if unicode.IsUpper(rune(name[0])) {
+		return Con{Name: name, Rigid: true}, nil
+	} else if _, ok := sp.records[name]; ok {
+		return Con{Name: name}, nil
+	}
+	return nil, errors.New("unknown type '" + name + "'")
+}
+
+// parseSignature parses a signature such as `[list<T>, number] -> T`, given the record types that have been declared
+func parseSignature(text string, records map[string][]string) (Fun, error) {
+	sp := sigParser{text: text, idx: 0, records: records}
+
+	f, err := sp.parseFun()
+	if err != nil {
+		return Fun{}, err
+	}
+
+	sp.skipSpace()
+	if sp.idx != len(sp.text) {
+		return Fun{}, fmt.Errorf("unexpected '%s' at the end of the signature", sp.text[sp.idx:])
+	}
+
+	return f, nil
+}
+
+// isSignature reports whether a doc comment is meant as a signature, rather than plain documentation
+func isSignature(doc string) bool {
+	return len(doc) > 0 && doc[0] == '['
+}
@@ -0,0 +1,357 @@
+package checker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Type interface {
+	Str() string
+}
+
+// Con is a concrete type such as `number` or `list<T>`;
+// a Rigid Con is a generic from a signature, seen from inside the function it belongs to
+type Con struct {
+	Name  string
+	Args  []Type
+	Rigid bool
+}
+
+func (t Con) Str() string {
+	if len(t.Args) == 0 && t.Name != "tuple" {
+		return t.Name
+	}
+
+	args := []string{}
+	for _, a := range t.Args {
+		args = append(args, a.Str())
+	}
+	if t.Name == "tuple" {
+		if len(args) == 1 {
+			return "(" + args[0] + ",)"
+		}
+		return "(" + strings.Join(args, ", ") + ")"
+	}
+	return t.Name + "<" + strings.Join(args, ", ") + ">"
+}
+
+// Var is a type that hasn't been worked out yet; ref is set once it has
+type Var struct {
+	id    int
+	level int
+	ref   Type
+}
+
+func (t *Var) Str() string {
+	if t.ref != nil {
+		return t.ref.Str()
+	}
+	return varName(t.id)
+}
+
+type Fun struct {
+	Params []Type
+	Ret    Type
+}
+
+func (t Fun) Str() string {
+	params := []string{}
+	for _, p := range t.Params {
+		params = append(params, p.Str())
+	}
+	return "[" + strings.Join(params, ", ") + "] -> " + t.Ret.Str()
+}
+
+// Any is compatible with every type, and is used wherever the checker can't know better
+type Any struct{}
+
+func (t Any) Str() string {
+	return "any"
+}
+
+// Builtin is a builtin function whose type depends on its arguments, such as `head`
+type Builtin struct {
+	Name string
+}
+
+func (t Builtin) Str() string {
+	return "builtin<" + t.Name + ">"
+}
+
+var (
+	Number  = Con{Name: "number"}
+	String  = Con{Name: "string"}
+	Char    = Con{Name: "char"}
+	Boolean = Con{Name: "boolean"}
+	Bytes   = Con{Name: "bytes"}
+)
+
+func List(elem Type) Type {
+	return Con{Name: "list", Args: []Type{elem}}
+}
+
+func Vector(elem Type) Type {
+	return Con{Name: "vector", Args: []Type{elem}}
+}
+
+func Set(elem Type) Type {
+	return Con{Name: "set", Args: []Type{elem}}
+}
+
+func Seq(elem Type) Type {
+	return Con{Name: "sequence", Args: []Type{elem}}
+}
+
+// Tuple is written `(T, U)`, and only fits tuples with the same number of elements
+func Tuple(elems ...Type) Type {
+	return Con{Name: "tuple", Args: elems}
+}
+
+// Option is the type of `some [x]` and `none`
+func Option(elem Type) Type {
+	return Con{Name: "option", Args: []Type{elem}}
+}
+
+func Map(key, value Type) Type {
+	return Con{Name: "map", Args: []Type{key, value}}
+}
+
+// elemType returns the type of the elements of an indexable type
+func elemType(t Con) (Type, bool) {
+	switch t.Name {
+	case "string":
+		return Char, true
+	case "bytes":
+		return Number, true
+	case "list", "vector":
+		return t.Args[0], true
+	}
+	return nil, false
+}
+
+// itemType returns the type of the elements of a type that can be walked as a sequence, which indexable types, sets and sequences can
+func itemType(t Con) (Type, bool) {
+	if t.Name == "sequence" || t.Name == "set" {
+		return t.Args[0], true
+	}
+	return elemType(t)
+}
+
+func varName(id int) string {
+	name := string(rune('a' + id%26))
+	if id >= 26 {
+		name += strings.Repeat("'", id/26)
+	}
+	return name
+}
+
+// prune follows bound type variables to the type they stand for
+func prune(t Type) Type {
+	for {
+		v, ok := t.(*Var)
+		if !ok || v.ref == nil {
+			return t
+		}
+		t = v.ref
+	}
+}
+
+// occurs reports whether v appears in t, lowering the level of any variable in t to v's as it goes
+func occurs(v *Var, t Type) bool {
+	switch t := prune(t).(type) {
+	case *Var:
+		if t == v {
+			return true
+		}
+		t.level = min(t.level, v.level)
+	case Con:
+		for _, a := range t.Args {
+			if occurs(v, a) {
+				return true
+			}
+		}
+	case Fun:
+		for _, p := range t.Params {
+			if occurs(v, p) {
+				return true
+			}
+		}
+		return occurs(v, t.Ret)
+	}
+	return false
+}
+
+func mismatch(a, b Type) error {
+	return fmt.Errorf("expected %s, but found %s", showAll([]any{a, b})...)
+}
+
+// unify makes a and b the same type, or reports why they can't be
+func unify(a, b Type) error {
+	a, b = prune(a), prune(b)
+
+	if _, ok := a.(Any); ok {
+		return nil
+	} else if _, ok := b.(Any); ok {
+		return nil
+	}
+
+	if v, ok := a.(*Var); ok {
+		if bv, ok := b.(*Var); ok && bv == v {
+			return nil
+		} else if occurs(v, b) {
+			return fmt.Errorf("cannot construct the infinite type %s = %s", showAll([]any{v, b})...)
+		}
+		v.ref = b
+		return nil
+	} else if _, ok := b.(*Var); ok {
+		return unify(b, a)
+	}
+
+	switch a := a.(type) {
+	case Con:
+		if _, ok := b.(Fun); ok {
+			return unify(b, a)
+		}
+
+		bc, ok := b.(Con)
+		if !ok || bc.Name != a.Name || len(bc.Args) != len(a.Args) {
+			return mismatch(a, b)
+		}
+		for i := range a.Args {
+			if err := unify(a.Args[i], bc.Args[i]); err != nil {
+				return mismatch(a, b)
+			}
+		}
+		return nil
+	case Fun:
+		if _, ok := b.(Builtin); ok {
+			return nil
+		}
+
+		// calling a list, string or vector indexes it, so they can stand in for functions from numbers to their elements
+		if bc, ok := b.(Con); ok && len(a.Params) == 1 {
+			if elem, ok := elemType(bc); ok {
+				if unify(a.Params[0], Number) != nil || unify(a.Ret, elem) != nil {
+					return mismatch(a, b)
+				}
+				return nil
+			}
+		}
+
+		bf, ok := b.(Fun)
+		if !ok || len(bf.Params) != len(a.Params) {
+			return mismatch(a, b)
+		}
+		for i := range a.Params {
+			if err := unify(a.Params[i], bf.Params[i]); err != nil {
+				return mismatch(a, b)
+			}
+		}
+		if err := unify(a.Ret, bf.Ret); err != nil {
+			return mismatch(a, b)
+		}
+		return nil
+	case Builtin:
+		switch b.(type) {
+		case Fun, Builtin:
+			return nil
+		}
+	}
+
+	return mismatch(a, b)
+}
+
+// scheme is a type that may be instantiated afresh at every use
+type scheme struct {
+	t          Type
+	quantified []*Var
+	generic    bool // whether the rigid generics from the signature should be instantiated
+}
+
+func mono(t Type) scheme {
+	return scheme{t: t, quantified: nil, generic: false}
+}
+
+// instantiate copies t, replacing quantified variables and rigid generics with the matching fresh variables
+func instantiate(t Type, vars map[*Var]Type, generics map[string]Type, fresh func() Type) Type {
+	switch t := prune(t).(type) {
+	case *Var:
+		if nv, ok := vars[t]; ok {
+			return nv
+		}
+		return t
+	case Con:
+		if t.Rigid && generics != nil {
+			if _, ok := generics[t.Name]; !ok {
+				generics[t.Name] = fresh()
+			}
+			return generics[t.Name]
+		}
+
+		args := []Type{}
+		for _, a := range t.Args {
+			args = append(args, instantiate(a, vars, generics, fresh))
+		}
+		return Con{Name: t.Name, Args: args, Rigid: t.Rigid}
+	case Fun:
+		params := []Type{}
+		for _, p := range t.Params {
+			params = append(params, instantiate(p, vars, generics, fresh))
+		}
+		return Fun{Params: params, Ret: instantiate(t.Ret, vars, generics, fresh)}
+	}
+	return t
+}
+
+// rename replaces the variables in t that are still unknown with generics, naming each one the first time it's met with label
+func rename(t Type, names map[*Var]Type, label func(n int) string) Type {
+	switch t := prune(t).(type) {
+	case *Var:
+		if _, ok := names[t]; !ok {
+			names[t] = Con{Name: label(len(names)), Rigid: true}
+		}
+		return names[t]
+	case Con:
+		args := []Type{}
+		for _, a := range t.Args {
+			args = append(args, rename(a, names, label))
+		}
+		return Con{Name: t.Name, Args: args, Rigid: t.Rigid}
+	case Fun:
+		params := []Type{}
+		for _, p := range t.Params {
+			params = append(params, rename(p, names, label))
+		}
+		return Fun{Params: params, Ret: rename(t.Ret, names, label)}
+	default:
+		return t
+	}
+}
+
+// Show formats a type for the user, naming the type variables that are still unknown T, U, V and so on
+func Show(t Type) string {
+	return rename(t, map[*Var]Type{}, func(n int) string {
+		name := string(rune('T' + n%7))
+		if n >= 7 {
+			name += strconv.Itoa(n / 7)
+		}
+		return name
+	}).Str()
+}
+
+/*
+showAll formats the types among the arguments of a message, naming the type variables that are still unknown a, b, c and so on,
+in the order they appear; the names are shared by all of the types, and don't depend on how many variables the checker has made.
+*/
+func showAll(a []any) []any {
+	names := map[*Var]Type{}
+	shown := []any{}
+	for _, v := range a {
+		if t, ok := v.(Type); ok {
+			v = rename(t, names, varName).Str()
+		}
+		shown = append(shown, v)
+	}
+	return shown
+}
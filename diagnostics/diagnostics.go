@@ -0,0 +1,236 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type Severity uint8
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+func (s Severity) Str() string {
+	return []string{
+		"error",
+		"warning",
+		"note",
+	}[s]
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Str())
+}
+
+// errors that didn't come from the source, such as a file that couldn't be read
+const (
+	Generic = "E000"
+)
+
+// lexical errors
+const (
+	IllegalCharacter    = "E001"
+	UnterminatedLiteral = "E002"
+	InvalidEscape       = "E003"
+	InvalidNumber       = "E004"
+	InvalidChar         = "E005"
+	UnterminatedComment = "E006"
+)
+
+// syntax errors
+const (
+	UnexpectedToken    = "E100"
+	UnexpectedEnd      = "E101"
+	InvalidReference   = "E102"
+	DuplicateParameter = "E103"
+	InvalidTypeName    = "E104"
+)
+
+// type errors, found by the checker
+const (
+	TypeMismatch     = "E200"
+	Undefined        = "E201"
+	ArityMismatch    = "E202"
+	InvalidSignature = "E203"
+)
+
+// warnings, found by the checker
+const (
+	NonExhaustiveMatch = "W001"
+)
+
+// errors that happen while the program runs
+const (
+	Runtime = "E300"
+)
+
+/*
+Diagnostic is a problem found somewhere in the source.
+Lines and columns start at 1, and the span includes both ends;
+a line of 0 or less means the position isn't known.
+Errors that happen while the program runs also have a kind, such as "type error", which Opal code that catches them can test for.
+*/
+type Diagnostic struct {
+	File     string   `json:"file"`
+	StartLn  int      `json:"startLine"`
+	StartCol int      `json:"startCol"`
+	EndLn    int      `json:"endLine"`
+	EndCol   int      `json:"endCol"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Notes    []string `json:"notes"`
+	Kind     string   `json:"kind,omitempty"`
+}
+
+func New(severity Severity, code string, ln, startCol, endCol int, format string, a ...any) Diagnostic {
+	return Diagnostic{
+		File:     "",
+		StartLn:  ln,
+		StartCol: startCol,
+		EndLn:    ln,
+		EndCol:   max(startCol, endCol),
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, a...),
+		Notes:    []string{},
+		Kind:     "",
+	}
+}
+
+func Errorf(code string, ln, startCol, endCol int, format string, a ...any) Diagnostic {
+	return New(Error, code, ln, startCol, endCol, format, a...)
+}
+
+// From returns the diagnostic err is or wraps, or turns err into one without a position
+func From(err error) Diagnostic {
+	var d Diagnostic
+	if errors.As(err, &d) {
+		return d
+	}
+	return Errorf(Generic, 0, 0, 0, "%s", err.Error())
+}
+
+// AnyErrors reports whether any of errs is an error, rather than a warning or a note
+func AnyErrors(errs []error) bool {
+	for _, err := range errs {
+		if From(err).Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+func (d Diagnostic) WithNote(format string, a ...any) Diagnostic {
+	d.Notes = append(append([]string{}, d.Notes...), fmt.Sprintf(format, a...))
+	return d
+}
+
+func (d Diagnostic) WithKind(kind string) Diagnostic {
+	d.Kind = kind
+	return d
+}
+
+func (d Diagnostic) WithFile(file string) Diagnostic {
+	d.File = file
+	return d
+}
+
+func (d Diagnostic) HasPos() bool {
+	return d.StartLn > 0
+}
+
+// pos formats where the diagnostic is, as `file:line:col`
+func (d Diagnostic) pos() string {
+	parts := []string{}
+	if d.File != "" {
+		parts = append(parts, d.File)
+	}
+	if d.HasPos() {
+		parts = append(parts, strconv.Itoa(d.StartLn), strconv.Itoa(d.StartCol))
+	}
+	return strings.Join(parts, ":")
+}
+
+func (d Diagnostic) header() string {
+	return d.Severity.Str() + "[" + d.Code + "]: " + d.Message
+}
+
+func (d Diagnostic) Error() string {
+	if pos := d.pos(); pos != "" {
+		return pos + ": " + d.header()
+	}
+	return d.header()
+}
+
+/*
+Render formats the diagnostic with the line of source it points at, underlining the span with carets:
+
+	error[E200]: argument 1 of 'fib' should be number, but is string
+	 --> fib.op:3:6
+	  |
+	3 | say [fib ["ten"]];
+	  |      ^^^
+	  = note: 'fib' has the type [number] -> number
+*/
+func (d Diagnostic) Render(source string) string {
+	var b strings.Builder
+	b.WriteString(d.header() + "\n")
+
+	lines := strings.Split(source, "\n")
+	if !d.HasPos() || d.StartLn > len(lines) {
+		if pos := d.pos(); pos != "" {
+			b.WriteString(" --> " + pos + "\n")
+		}
+		for _, note := range d.Notes {
+			b.WriteString(" = note: " + note + "\n")
+		}
+		return b.String()
+	}
+
+	line := strings.TrimRight(lines[d.StartLn-1], "\r")
+	ln := strconv.Itoa(d.StartLn)
+	gutter := strings.Repeat(" ", len(ln))
+
+	b.WriteString(gutter + "--> " + d.pos() + "\n")
+	b.WriteString(gutter + " |\n")
+	b.WriteString(ln + " | " + line + "\n")
+
+	// spans over several lines are only underlined to the end of the first
+	end := d.EndCol
+	if d.EndLn != d.StartLn {
+		end = utf8.RuneCountInString(line)
+	}
+	width := max(end-d.StartCol+1, 1)
+
+	// keep tabs in the padding, so the carets line up with the source
+	pad := []rune{}
+	for _, r := range line {
+		if len(pad) >= d.StartCol-1 {
+			break
+		} else if r == '\t' {
+			pad = append(pad, '\t')
+		} else {
+			pad = append(pad, ' ')
+		}
+	}
+
+	b.WriteString(gutter + " | " + string(pad) + strings.Repeat("^", width) + "\n")
+	for _, note := range d.Notes {
+		b.WriteString(gutter + " = note: " + note + "\n")
+	}
+
+	return b.String()
+}
+
+func (d Diagnostic) JSON() string {
+	b, _ := json.Marshal(d)
+	return string(b)
+}
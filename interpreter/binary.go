@@ -0,0 +1,57 @@
+package interpreter
+
+import (
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/bytestype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/stringtype"
+)
+
+// toBytes returns the argument of a bytes builtin as bytes
+func toBytes(name string, val valuetypes.ValueType) (bytestype.BytesType, error) {
+	b, ok := val.(bytestype.BytesType)
+	if !ok {
+		return bytestype.BytesType{}, valuetypes.Errorf(valuetypes.TypeError, "'%s' expects bytes, but was given type '%s'", name, val.Type())
+	}
+	return b, nil
+}
+
+// toText returns the argument of a builtin that decodes text as a Go string
+func toText(name string, val valuetypes.ValueType) (string, error) {
+	s, ok := val.(stringtype.StringType)
+	if !ok {
+		return "", valuetypes.Errorf(valuetypes.TypeError, "'%s' expects a string, but was given type '%s'", name, val.Type())
+	}
+	return s.Lit().(string), nil
+}
+
+/*
+makeBytes is `bytes [x]`: a string becomes the bytes of its UTF-8 encoding,
+and a list, vector or sequence of numbers from 0 to 255 becomes those bytes.
+*/
+func makeBytes(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	switch v := val.(type) {
+	case bytestype.BytesType:
+		return v, nil
+	case stringtype.StringType:
+		return bytestype.New([]byte(v.Lit().(string))), nil
+	}
+
+	s, err := toSeq("bytes", val)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.Values()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, len(values))
+	for _, v := range values {
+		b, ok := bytestype.ToByte(v)
+		if !ok {
+			return nil, valuetypes.Errorf(valuetypes.TypeError, "'bytes' expects numbers from 0 to 255, but was given %s", v.Fmt())
+		}
+		data = append(data, b)
+	}
+	return bytestype.New(data), nil
+}
@@ -0,0 +1,524 @@
+package interpreter
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/voidwyrm-2/opal/diagnostics"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/bytestype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/chartype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/funtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/listtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/maptype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/numbertype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/recordtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/seqtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/settype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/stringtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/tupletype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/vectortype"
+)
+
+func fmtArgs(args []valuetypes.ValueType) string {
+	formatted := []string{}
+	for _, a := range args {
+		formatted = append(formatted, a.Fmt())
+	}
+	return strings.Join(formatted, " ")
+}
+
+// toInt returns the value as an int, if it's an integer number
+func toInt(val valuetypes.ValueType) (int, bool) {
+	if n, ok := val.(numbertype.NumberType); ok {
+		i, ok := n.Int()
+		return int(i), ok
+	}
+	return 0, false
+}
+
+// toMap returns the argument of a map builtin as a map
+func toMap(name string, val valuetypes.ValueType) (maptype.MapType, error) {
+	m, ok := val.(maptype.MapType)
+	if !ok {
+		return maptype.MapType{}, valuetypes.Errorf(valuetypes.TypeError, "'%s' expects a map, but was given type '%s'", name, val.Type())
+	}
+	return m, nil
+}
+
+// toOption returns the argument of an option builtin as the value it holds, or false if it's `none`
+func toOption(name string, val valuetypes.ValueType) (valuetypes.ValueType, bool, error) {
+	r, ok := val.(recordtype.RecordType)
+	if union, isVariant := r.Shape().Union(); !ok || !isVariant || union != "option" {
+		return nil, false, valuetypes.Errorf(valuetypes.TypeError, "'%s' expects an option, but was given type '%s'", name, val.Type())
+	}
+
+	v, ok := r.Option()
+	return v, ok, nil
+}
+
+/*
+raise is `raise [message]`, `raise [message, kind]`, or `raise [err]`;
+an error value that was caught is raised again from where it first happened, so it isn't mistaken for a new one.
+*/
+func raise(args []valuetypes.ValueType) error {
+	if len(args) < 1 || len(args) > 2 {
+		return valuetypes.Errorf(valuetypes.ArityError, "function 'raise' expects 1 or 2 argument(s), but received %d", len(args))
+	}
+
+	if r, ok := args[0].(recordtype.RecordType); ok && r.IsError() && len(args) == 1 {
+		message, _ := r.Field("message")
+		kind, _ := r.Field("kind")
+		line, _ := r.Field("line")
+		column, _ := r.Field("column")
+
+		ln, _ := toInt(line)
+		col, _ := toInt(column)
+		if ln <= 0 {
+			return valuetypes.NewError(kind.Lit().(string), message.Lit().(string))
+		}
+		return diagnostics.Errorf(diagnostics.Runtime, ln, col, col, "%s", message.Lit().(string)).WithKind(kind.Lit().(string))
+	}
+
+	message, ok := args[0].(stringtype.StringType)
+	if !ok {
+		return valuetypes.Errorf(valuetypes.TypeError, "'raise' expects a message or an error, but was given type '%s'", args[0].Type())
+	}
+
+	kind := valuetypes.GeneralError
+	if len(args) == 2 {
+		k, ok := args[1].(stringtype.StringType)
+		if !ok {
+			return valuetypes.Errorf(valuetypes.TypeError, "'raise' expects the kind of error to be a string, but was given type '%s'", args[1].Type())
+		}
+		kind = k.Lit().(string)
+	}
+	return valuetypes.NewError(kind, message.Lit().(string))
+}
+
+func builtins() map[string]valuetypes.ValueType {
+	return map[string]valuetypes.ValueType{
+		"say": funtype.New("say", funtype.Variadic, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			fmt.Println(fmtArgs(args))
+			return tupletype.Unit(), nil
+		}),
+		"print": funtype.New("print", funtype.Variadic, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			fmt.Print(fmtArgs(args))
+			return tupletype.Unit(), nil
+		}),
+		"some": funtype.New("some", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			return recordtype.Some(args[0]), nil
+		}),
+		"none": recordtype.None(),
+		"unwrap": funtype.New("unwrap", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			v, ok, err := toOption("unwrap", args[0])
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				return nil, errors.New("cannot unwrap none")
+			}
+			return v, nil
+		}),
+		"default": funtype.New("default", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			v, ok, err := toOption("default", args[0])
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				return args[1], nil
+			}
+			return v, nil
+		}),
+		"raise": funtype.New("raise", funtype.Variadic, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			return nil, raise(args)
+		}),
+		"len": funtype.New("len", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			switch v := args[0].(type) {
+			case valuetypes.Indexable:
+				return numbertype.NewInt(int64(v.Len())), nil
+			case seqtype.SeqType:
+				n, err := v.Len()
+				if err != nil {
+					return nil, err
+				}
+				return numbertype.NewInt(int64(n)), nil
+			case maptype.MapType:
+				return numbertype.NewInt(int64(v.Len())), nil
+			case settype.SetType:
+				return numbertype.NewInt(int64(v.Len())), nil
+			}
+			return nil, valuetypes.Errorf(valuetypes.TypeError, "type '%s' has no length", args[0].Type())
+		}),
+		// the head of something empty is none, rather than an error
+		"head": funtype.New("head", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			switch v := args[0].(type) {
+			case stringtype.StringType:
+				// only the first character is decoded, so taking the head of a long string is as quick as a short one
+				if v.Empty() {
+					return recordtype.None(), nil
+				}
+				head, err := v.Head()
+				if err != nil {
+					return nil, err
+				}
+				return recordtype.Some(head), nil
+			case valuetypes.Indexable:
+				if v.Len() == 0 {
+					return recordtype.None(), nil
+				}
+				head, err := v.Index(0)
+				if err != nil {
+					return nil, err
+				}
+				return recordtype.Some(head), nil
+			case seqtype.SeqType:
+				if empty, err := v.Empty(); err != nil {
+					return nil, err
+				} else if empty {
+					return recordtype.None(), nil
+				}
+				head, err := v.Head()
+				if err != nil {
+					return nil, err
+				}
+				return recordtype.Some(head), nil
+			}
+			return nil, valuetypes.Errorf(valuetypes.TypeError, "type '%s' has no head", args[0].Type())
+		}),
+		"tail": funtype.New("tail", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			switch v := args[0].(type) {
+			case listtype.ListType:
+				return v.Tail()
+			case stringtype.StringType:
+				return v.Tail()
+			case vectortype.VectorType:
+				return v.Tail()
+			case bytestype.BytesType:
+				return v.Tail()
+			case seqtype.SeqType:
+				return v.Tail()
+			}
+			return nil, valuetypes.Errorf(valuetypes.TypeError, "type '%s' has no tail", args[0].Type())
+		}),
+		"at": funtype.New("at", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			i, ok := toInt(args[1])
+			if !ok {
+				return nil, errors.New("'at' expects an integer index, but was given " + args[1].Fmt())
+			}
+
+			if xs, ok := args[0].(valuetypes.Indexable); ok {
+				return xs.Index(i)
+			}
+			return nil, valuetypes.Errorf(valuetypes.TypeError, "type '%s' cannot be indexed", args[0].Type())
+		}),
+		"slice": funtype.New("slice", 3, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			start, ok := toInt(args[1])
+			end, endOk := toInt(args[2])
+			if !ok || !endOk {
+				return nil, errors.New("'slice' expects integer indices, but was given " + args[1].Fmt() + " and " + args[2].Fmt())
+			}
+
+			switch v := args[0].(type) {
+			case vectortype.VectorType:
+				return v.Slice(start, end)
+			case bytestype.BytesType:
+				return v.Slice(start, end)
+			}
+			return nil, valuetypes.Errorf(valuetypes.TypeError, "'slice' expects a vector or bytes, but was given type '%s'", args[0].Type())
+		}),
+		"vector": funtype.New("vector", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			l, ok := args[0].(listtype.ListType)
+			if !ok {
+				return nil, valuetypes.Errorf(valuetypes.TypeError, "'vector' expects a list, but was given type '%s'", args[0].Type())
+			}
+			return vectortype.New(l.Values()...), nil
+		}),
+		"list": funtype.New("list", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			v, ok := args[0].(vectortype.VectorType)
+			if !ok {
+				return nil, valuetypes.Errorf(valuetypes.TypeError, "'list' expects a vector, but was given type '%s'", args[0].Type())
+			}
+			return listtype.New(v.Values()...), nil
+		}),
+		"div": funtype.New("div", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			n, ok := args[0].(numbertype.NumberType)
+			if !ok {
+				return nil, valuetypes.Errorf(valuetypes.TypeError, "'div' expects a number, but was given type '%s'", args[0].Type())
+			}
+			return n.IntDiv(args[1])
+		}),
+		"get": funtype.New("get", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			m, err := toMap("get", args[0])
+			if err != nil {
+				return nil, err
+			}
+			v, ok, err := m.Get(args[1])
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				return recordtype.None(), nil
+			}
+			return recordtype.Some(v), nil
+		}),
+		// has tells whether a map has a key, or a set has an element
+		"has": funtype.New("has", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			if s, ok := args[0].(settype.SetType); ok {
+				ok, err := s.Has(args[1])
+				if err != nil {
+					return nil, err
+				}
+				return booltype.New(ok), nil
+			}
+
+			m, err := toMap("has", args[0])
+			if err != nil {
+				return nil, err
+			}
+			ok, err := m.Has(args[1])
+			if err != nil {
+				return nil, err
+			}
+			return booltype.New(ok), nil
+		}),
+		"put": funtype.New("put", 3, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			m, err := toMap("put", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return m.Put(args[1], args[2])
+		}),
+		"delete": funtype.New("delete", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			m, err := toMap("delete", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return m.Delete(args[1])
+		}),
+		"keys": funtype.New("keys", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			m, err := toMap("keys", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return listtype.New(m.Keys()...), nil
+		}),
+		"values": funtype.New("values", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			m, err := toMap("values", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return listtype.New(m.Values()...), nil
+		}),
+		"merge": funtype.New("merge", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			a, err := toMap("merge", args[0])
+			if err != nil {
+				return nil, err
+			}
+			b, err := toMap("merge", args[1])
+			if err != nil {
+				return nil, err
+			}
+			return a.Merge(b), nil
+		}),
+		"range": funtype.New("range", funtype.Variadic, rangeSeq),
+		"iterate": funtype.New("iterate", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			f, err := toFun("iterate", args[1])
+			if err != nil {
+				return nil, err
+			}
+			return iterateSeq(args[0], f), nil
+		}),
+		"set": funtype.New("set", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			s, err := toSeq("set", args[0])
+			if err != nil {
+				return nil, err
+			}
+			values, err := s.Values()
+			if err != nil {
+				return nil, err
+			}
+			return settype.New(values...)
+		}),
+		"cycle": funtype.New("cycle", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			s, err := toSeq("cycle", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return cycleSeq(s), nil
+		}),
+		"map": funtype.New("map", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			s, err := toSeq("map", args[0])
+			if err != nil {
+				return nil, err
+			}
+			f, err := toFun("map", args[1])
+			if err != nil {
+				return nil, err
+			}
+			return s.Map(func(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+				return f.Call([]valuetypes.ValueType{val})
+			}), nil
+		}),
+		"filter": funtype.New("filter", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			s, err := toSeq("filter", args[0])
+			if err != nil {
+				return nil, err
+			}
+			f, err := toFun("filter", args[1])
+			if err != nil {
+				return nil, err
+			}
+			return s.Filter(keeps(f)), nil
+		}),
+		"find": funtype.New("find", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			s, err := toSeq("find", args[0])
+			if err != nil {
+				return nil, err
+			}
+			f, err := toFun("find", args[1])
+			if err != nil {
+				return nil, err
+			}
+
+			found, err := s.Filter(keeps(f)).Take(1)
+			if err != nil {
+				return nil, err
+			} else if len(found) == 0 {
+				return recordtype.None(), nil
+			}
+			return recordtype.Some(found[0]), nil
+		}),
+		"take": funtype.New("take", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			s, err := toSeq("take", args[0])
+			if err != nil {
+				return nil, err
+			}
+			n, ok := toInt(args[1])
+			if !ok || n < 0 {
+				return nil, errors.New("'take' expects a count that's a non-negative integer, but was given " + args[1].Fmt())
+			}
+
+			values, err := s.Take(n)
+			if err != nil {
+				return nil, err
+			}
+			return listtype.New(values...), nil
+		}),
+		"lines": funtype.New("lines", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			path, ok := args[0].(stringtype.StringType)
+			if !ok {
+				return nil, valuetypes.Errorf(valuetypes.TypeError, "'lines' expects a string, but was given type '%s'", args[0].Type())
+			}
+			return linesSeq(path.Lit().(string)), nil
+		}),
+		"ord": funtype.New("ord", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			c, ok := args[0].(chartype.CharType)
+			if !ok {
+				return nil, valuetypes.Errorf(valuetypes.TypeError, "'ord' expects a char, but was given type '%s'", args[0].Type())
+			}
+			return numbertype.NewInt(int64(c.CodePoint())), nil
+		}),
+		"chr": funtype.New("chr", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			cp, ok := toInt(args[0])
+			if !ok {
+				return nil, errors.New("'chr' expects an integer, but was given " + args[0].Fmt())
+			}
+			return chartype.FromCodePoint(cp)
+		}),
+		"bytes": funtype.New("bytes", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			return makeBytes(args[0])
+		}),
+		// text decodes bytes as UTF-8
+		"text": funtype.New("text", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			b, err := toBytes("text", args[0])
+			if err != nil {
+				return nil, err
+			} else if !utf8.Valid(b.Bytes()) {
+				return nil, errors.New("the bytes aren't valid UTF-8 text")
+			}
+			return stringtype.New(string(b.Bytes())), nil
+		}),
+		"hex": funtype.New("hex", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			b, err := toBytes("hex", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return stringtype.New(hex.EncodeToString(b.Bytes())), nil
+		}),
+		"unhex": funtype.New("unhex", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			s, err := toText("unhex", args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, errors.New("invalid hexadecimal: " + err.Error())
+			}
+			return bytestype.New(data), nil
+		}),
+		"base64": funtype.New("base64", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			b, err := toBytes("base64", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return stringtype.New(base64.StdEncoding.EncodeToString(b.Bytes())), nil
+		}),
+		"unbase64": funtype.New("unbase64", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			s, err := toText("unbase64", args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, errors.New("invalid base64: " + err.Error())
+			}
+			return bytestype.New(data), nil
+		}),
+		"grabbytes": funtype.New("grabbytes", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			path, err := toText("grabbytes", args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, valuetypes.NewError(valuetypes.IOError, err.Error())
+			}
+			return bytestype.New(data), nil
+		}),
+		// writebytes replaces the file at the path with the bytes, creating it if it doesn't exist
+		"writebytes": funtype.New("writebytes", 2, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			path, err := toText("writebytes", args[0])
+			if err != nil {
+				return nil, err
+			}
+			b, err := toBytes("writebytes", args[1])
+			if err != nil {
+				return nil, err
+			}
+
+			if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+				return nil, valuetypes.NewError(valuetypes.IOError, err.Error())
+			}
+			return tupletype.Unit(), nil
+		}),
+		"grabfile": funtype.New("grabfile", 1, func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+			path, ok := args[0].(stringtype.StringType)
+			if !ok {
+				return nil, valuetypes.Errorf(valuetypes.TypeError, "'grabfile' expects a string, but was given type '%s'", args[0].Type())
+			}
+
+			content, err := os.ReadFile(path.Lit().(string))
+			if err != nil {
+				return nil, valuetypes.NewError(valuetypes.IOError, err.Error())
+			}
+			return stringtype.New(string(content)), nil
+		}),
+	}
+}
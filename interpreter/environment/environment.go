@@ -0,0 +1,99 @@
+package environment
+
+import (
+	"errors"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/recordtype"
+)
+
+// frame holds what a single function call was given
+type frame struct {
+	self valuetypes.ValueType
+	args []valuetypes.ValueType
+}
+
+/*
+Environment is one lexical scope.
+Lookups walk outward through the parent scopes, so inner bindings shadow outer ones,
+but a name can only be bound once per scope.
+*/
+type Environment struct {
+	vars   map[string]valuetypes.ValueType
+	shapes map[string]recordtype.Shape // the record types and variants made by the constructors and variants bound in this scope
+	parent *Environment
+	frame  *frame
+}
+
+// New creates a scope nested inside of parent; parent may be nil for the outermost scope
+func New(parent *Environment) *Environment {
+	return &Environment{vars: map[string]valuetypes.ValueType{}, shapes: map[string]recordtype.Shape{}, parent: parent, frame: nil}
+}
+
+// NewFrame creates the scope for a call to self, nested inside of the scope self was defined in
+func NewFrame(parent *Environment, self valuetypes.ValueType, args []valuetypes.ValueType) *Environment {
+	env := New(parent)
+	env.frame = &frame{self: self, args: args}
+	return env
+}
+
+func (env *Environment) Get(name string) (valuetypes.ValueType, bool) {
+	for e := env; e != nil; e = e.parent {
+		if v, ok := e.vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (env *Environment) Define(name string, value valuetypes.ValueType) error {
+	if _, ok := env.vars[name]; ok {
+		return errors.New("'" + name + "' is already defined in this scope")
+	}
+	env.vars[name] = value
+	return nil
+}
+
+// DefineShape records that name, a constructor or variant defined in this scope, makes values of shape
+func (env *Environment) DefineShape(name string, shape recordtype.Shape) {
+	env.shapes[name] = shape
+}
+
+// Shape returns the shape name makes, if the innermost binding of name is a constructor or variant
+func (env *Environment) Shape(name string) (recordtype.Shape, bool) {
+	for e := env; e != nil; e = e.parent {
+		if shape, ok := e.shapes[name]; ok {
+			return shape, true
+		} else if _, ok := e.vars[name]; ok {
+			return recordtype.Shape{}, false
+		}
+	}
+	return recordtype.Shape{}, false
+}
+
+func (env *Environment) nearestFrame() *frame {
+	for e := env; e != nil; e = e.parent {
+		if e.frame != nil {
+			return e.frame
+		}
+	}
+	return nil
+}
+
+// Arg returns the one-based argument of the innermost function call
+func (env *Environment) Arg(index int) (valuetypes.ValueType, bool) {
+	f := env.nearestFrame()
+	if f == nil || index < 1 || index > len(f.args) {
+		return nil, false
+	}
+	return f.args[index-1], true
+}
+
+// Self returns the function of the innermost function call
+func (env *Environment) Self() (valuetypes.ValueType, bool) {
+	f := env.nearestFrame()
+	if f == nil {
+		return nil, false
+	}
+	return f.self, true
+}
@@ -0,0 +1,48 @@
+package interpreter
+
+import (
+	"github.com/voidwyrm-2/opal/interpreter/environment"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/recordtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/tupletype"
+	"github.com/voidwyrm-2/opal/parser/nodes"
+)
+
+type Interpreter struct {
+	env *environment.Environment
+}
+
+func New() *Interpreter {
+	// builtins live in their own outer scope, so programs are free to shadow them
+	prelude := environment.New(nil)
+	for name, fn := range builtins() {
+		prelude.Define(name, fn)
+	}
+	for name, shape := range recordtype.Builtins() {
+		prelude.DefineShape(name, shape)
+	}
+	return &Interpreter{env: environment.New(prelude)}
+}
+
+// NewScope continues in a scope nested inside the current one, so what's defined from then on may shadow earlier definitions
+func (in *Interpreter) NewScope() {
+	in.env = environment.New(in.env)
+}
+
+// Eval runs a program and returns the value of its last statement
+func (in *Interpreter) Eval(program []nodes.Node) (valuetypes.ValueType, error) {
+	var result valuetypes.ValueType = tupletype.Unit()
+	for _, n := range program {
+		v, err := n.Execute(in.env)
+		if err != nil {
+			return nil, err
+		}
+		result = v
+	}
+	return result, nil
+}
+
+func (in *Interpreter) Run(program []nodes.Node) error {
+	_, err := in.Eval(program)
+	return err
+}
@@ -0,0 +1,227 @@
+package interpreter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/bytestype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/chartype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/funtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/listtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/numbertype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/seqtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/settype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/stringtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/vectortype"
+	"github.com/voidwyrm-2/opal/parser/nodes"
+)
+
+// toSeq returns the argument of a sequence builtin as a sequence, so lists, vectors, strings, sets and bytes can be used wherever sequences can
+func toSeq(name string, val valuetypes.ValueType) (seqtype.SeqType, error) {
+	switch v := val.(type) {
+	case seqtype.SeqType:
+		return v, nil
+	case listtype.ListType:
+		current := v
+		return seqtype.New(func() (valuetypes.ValueType, bool, error) {
+			if current.Len() == 0 {
+				return nil, false, nil
+			}
+			head, _ := current.Head()
+			current, _ = current.Tail()
+			return head, true, nil
+		}, true), nil
+	case vectortype.VectorType:
+		return seqtype.Of(v.Values()...), nil
+	case settype.SetType:
+		return seqtype.Of(v.Values()...), nil
+	case bytestype.BytesType:
+		return seqtype.Of(v.Values()...), nil
+	case stringtype.StringType:
+		// the characters are decoded one at a time, from the byte offset of the next one
+		str, offset := v.Lit().(string), 0
+		return seqtype.New(func() (valuetypes.ValueType, bool, error) {
+			if offset == len(str) {
+				return nil, false, nil
+			}
+			r, size := utf8.DecodeRuneInString(str[offset:])
+			offset += size
+			return chartype.New(r), true, nil
+		}, true), nil
+	}
+	return seqtype.SeqType{}, valuetypes.Errorf(valuetypes.TypeError, "'%s' expects a list, vector, string, set, bytes or sequence, but was given type '%s'", name, val.Type())
+}
+
+// toFun returns the argument of a builtin as a function
+func toFun(name string, val valuetypes.ValueType) (funtype.FunType, error) {
+	f, ok := val.(funtype.FunType)
+	if !ok {
+		return funtype.FunType{}, valuetypes.Errorf(valuetypes.TypeError, "'%s' expects a function, but was given type '%s'", name, val.Type())
+	}
+	return f, nil
+}
+
+/*
+rangeSeq makes the sequence for `range [start]`, `range [start, end]` or `range [start, end, step]`.
+Without an end it never stops; otherwise it stops before reaching end, counting down if step is negative.
+*/
+func rangeSeq(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, valuetypes.Errorf(valuetypes.ArityError, "function 'range' expects 1 to 3 argument(s), but received %d", len(args))
+	}
+
+	bounds := []numbertype.NumberType{}
+	for _, a := range args {
+		n, ok := a.(numbertype.NumberType)
+		if !ok {
+			return nil, valuetypes.Errorf(valuetypes.TypeError, "'range' expects numbers, but was given type '%s'", a.Type())
+		}
+		bounds = append(bounds, n)
+	}
+
+	step := numbertype.NewInt(1)
+	if len(bounds) == 3 {
+		step = bounds[2]
+	}
+	if step.IsZero() {
+		return nil, errors.New("the step of a range cannot be 0")
+	}
+
+	descending, err := step.LesserThan(numbertype.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+
+	var current valuetypes.ValueType = bounds[0]
+	return seqtype.New(func() (valuetypes.ValueType, bool, error) {
+		value := current
+		if len(bounds) > 1 {
+			var past valuetypes.ValueType
+			var err error
+			if descending.Lit() == true {
+				past, err = value.LesserThan(bounds[1])
+			} else {
+				past, err = value.GreaterThan(bounds[1])
+			}
+
+			if err != nil {
+				return nil, false, err
+			} else if eq, _ := value.Equals(bounds[1]); past.Lit() == true || eq.Lit() == true {
+				return nil, false, nil
+			}
+		}
+
+		next, err := value.Add(step)
+		if err != nil {
+			return nil, false, err
+		}
+		current = next
+		return value, true, nil
+	}, len(bounds) > 1), nil
+}
+
+// iterateSeq makes the sequence `x, f [x], f [f [x]], ...`
+func iterateSeq(x valuetypes.ValueType, f funtype.FunType) seqtype.SeqType {
+	var current valuetypes.ValueType
+	return seqtype.New(func() (valuetypes.ValueType, bool, error) {
+		if current == nil {
+			current = x
+			return current, true, nil
+		}
+
+		next, err := f.Call([]valuetypes.ValueType{current})
+		if err != nil {
+			return nil, false, err
+		}
+		current = next
+		return current, true, nil
+	}, false)
+}
+
+// cycleSeq repeats the elements of s forever; cycling an empty sequence gives an empty sequence
+func cycleSeq(s seqtype.SeqType) seqtype.SeqType {
+	current := s
+	return seqtype.New(func() (valuetypes.ValueType, bool, error) {
+		if empty, err := current.Empty(); err != nil {
+			return nil, false, err
+		} else if empty {
+			current = s
+			if empty, err := current.Empty(); err != nil || empty {
+				return nil, false, err
+			}
+		}
+
+		head, _ := current.Head()
+		current, _ = current.Tail()
+		return head, true, nil
+	}, false)
+}
+
+/*
+linesSeq streams the lines of a file, reading it a chunk at a time as the lines are needed.
+The file is opened for each chunk and closed again straight after, so a sequence that's never read to the end doesn't keep it open;
+lines can be any length, since a chunk that ends partway through one is kept until the next chunk finishes it.
+*/
+func linesSeq(path string) seqtype.SeqType {
+	const chunkSize = 64 * 1024
+
+	var pending []byte
+	var offset int64
+	eof := false
+
+	// fill reads the next chunk of the file onto the end of pending
+	fill := func() error {
+		file, err := os.Open(path)
+		if err != nil {
+			return valuetypes.NewError(valuetypes.IOError, err.Error())
+		}
+		defer file.Close()
+
+		chunk := make([]byte, chunkSize)
+		n, err := file.ReadAt(chunk, offset)
+		if err == io.EOF {
+			eof = true
+		} else if err != nil {
+			return valuetypes.NewError(valuetypes.IOError, err.Error())
+		}
+		pending = append(pending, chunk[:n]...)
+		offset += int64(n)
+		return nil
+	}
+
+	return seqtype.New(func() (valuetypes.ValueType, bool, error) {
+		for {
+			if i := bytes.IndexByte(pending, '\n'); i >= 0 {
+				line := pending[:i]
+				pending = pending[i+1:]
+				return stringtype.New(string(bytes.TrimSuffix(line, []byte("\r")))), true, nil
+			} else if eof {
+				if len(pending) == 0 {
+					return nil, false, nil
+				}
+				line := pending
+				pending = nil
+				return stringtype.New(string(bytes.TrimSuffix(line, []byte("\r")))), true, nil
+			}
+
+			if err := fill(); err != nil {
+				return nil, false, err
+			}
+		}
+	}, true)
+}
+
+// keeps adapts a predicate given to `filter` to the truthiness of what it returns
+func keeps(f funtype.FunType) func(val valuetypes.ValueType) (bool, error) {
+	return func(val valuetypes.ValueType) (bool, error) {
+		result, err := f.Call([]valuetypes.ValueType{val})
+		if err != nil {
+			return false, err
+		}
+		return nodes.Truthy(result), nil
+	}
+}
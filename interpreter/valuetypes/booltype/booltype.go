@@ -0,0 +1,90 @@
+package booltype
+
+import (
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+)
+
+type BoolType struct {
+	value bool
+}
+
+func New(value bool) BoolType {
+	return BoolType{value: value}
+}
+
+func (bt BoolType) Fmt() string {
+	if bt.value {
+		return "True"
+	}
+	return "False"
+}
+
+func (bt BoolType) Lit() any {
+	return bt.value
+}
+
+func (bt BoolType) Type() string {
+	return "boolean"
+}
+
+func (bt BoolType) Hash() (string, bool) {
+	return "b:" + bt.Fmt(), true
+}
+
+func (bt BoolType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "addition")
+}
+
+func (bt BoolType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "concatenation")
+}
+
+func (bt BoolType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "subtraction")
+}
+
+func (bt BoolType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "multiplication")
+}
+
+func (bt BoolType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "division")
+}
+
+func (bt BoolType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "modulus")
+}
+
+func (bt BoolType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "bitwise AND")
+}
+
+func (bt BoolType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "bitwise OR")
+}
+
+func (bt BoolType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "bitwise XOR")
+}
+
+func (bt BoolType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "boolean" {
+		return New(false), nil
+	}
+	return New(bt.value == val.(BoolType).value), nil
+}
+
+func (bt BoolType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "boolean" {
+		return New(true), nil
+	}
+	return New(bt.value != val.(BoolType).value), nil
+}
+
+func (bt BoolType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "greater than")
+}
+
+func (bt BoolType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "lesser than")
+}
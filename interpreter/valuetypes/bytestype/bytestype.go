@@ -0,0 +1,201 @@
+package bytestype
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/numbertype"
+)
+
+/*
+BytesType is binary data, written `b"..."`, whose elements are numbers from 0 to 255.
+The bytes are never changed once they're made, so slices can share them rather than copy them.
+*/
+type BytesType struct {
+	data []byte
+}
+
+func New(data []byte) BytesType {
+	return BytesType{data: append([]byte{}, data...)}
+}
+
+func (bt BytesType) Len() int {
+	return len(bt.data)
+}
+
+// Bytes returns a copy of the data
+func (bt BytesType) Bytes() []byte {
+	return append([]byte{}, bt.data...)
+}
+
+// Index returns the byte at index i as a number, counting from 0, or back from the end if i is negative
+func (bt BytesType) Index(i int) (valuetypes.ValueType, error) {
+	pos, ok := valuetypes.Position(i, bt.Len())
+	if !ok {
+		return nil, fmt.Errorf("index %d is out of range for bytes of length %d", i, bt.Len())
+	}
+	return numbertype.NewInt(int64(bt.data[pos])), nil
+}
+
+// Slice returns the bytes from index start up to, but not including, index end; negative indices count back from the end
+func (bt BytesType) Slice(start, end int) (BytesType, error) {
+	from, to := start, end
+	if from < 0 {
+		from += bt.Len()
+	}
+	if to < 0 {
+		to += bt.Len()
+	}
+
+	if from < 0 || to > bt.Len() || from > to {
+		return BytesType{}, fmt.Errorf("slice %d to %d is out of range for bytes of length %d", start, end, bt.Len())
+	}
+	return BytesType{data: bt.data[from:to:to]}, nil
+}
+
+func (bt BytesType) Tail() (BytesType, error) {
+	if bt.Len() == 0 {
+		return BytesType{}, errors.New("cannot take the tail of empty bytes")
+	}
+	return bt.Slice(1, bt.Len())
+}
+
+// Values returns the bytes as numbers
+func (bt BytesType) Values() []valuetypes.ValueType {
+	values := make([]valuetypes.ValueType, 0, bt.Len())
+	for _, b := range bt.data {
+		values = append(values, numbertype.NewInt(int64(b)))
+	}
+	return values
+}
+
+// ToByte returns val as a byte, if it's an integer that fits in one
+func ToByte(val valuetypes.ValueType) (byte, bool) {
+	n, ok := val.(numbertype.NumberType)
+	if !ok {
+		return 0, false
+	}
+	i, ok := n.Int()
+	return byte(i), ok && i >= 0 && i <= 255
+}
+
+// Fmt writes the bytes as a literal, with the ones that aren't printable ASCII escaped
+func (bt BytesType) Fmt() string {
+	var b strings.Builder
+	b.WriteString(`b"`)
+	for _, c := range bt.data {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteString(`\` + string(c))
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteString(`"`)
+	return b.String()
+}
+
+func (bt BytesType) Lit() any {
+	return bt.Bytes()
+}
+
+func (bt BytesType) Type() string {
+	return "bytes"
+}
+
+func (bt BytesType) Hash() (string, bool) {
+	return "bytes:" + string(bt.data), true
+}
+
+func (bt BytesType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "addition")
+}
+
+// Concat joins two lots of bytes, or adds a byte to the end; either way the bytes are copied, so bt is left as it was
+func (bt BytesType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if other, ok := val.(BytesType); ok {
+		return BytesType{data: append(bt.Bytes(), other.data...)}, nil
+	} else if b, ok := ToByte(val); ok {
+		return BytesType{data: append(bt.Bytes(), b)}, nil
+	}
+	return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot concatenate type '%s' with %s, as it isn't bytes or a number from 0 to 255", bt.Type(), val.Fmt())
+}
+
+func (bt BytesType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "subtraction")
+}
+
+func (bt BytesType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "multiplication")
+}
+
+func (bt BytesType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "division")
+}
+
+func (bt BytesType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "modulus")
+}
+
+func (bt BytesType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "bitwise AND")
+}
+
+func (bt BytesType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "bitwise OR")
+}
+
+func (bt BytesType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(bt, "bitwise XOR")
+}
+
+func (bt BytesType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, ok := val.(BytesType)
+	return booltype.New(ok && bytes.Equal(bt.data, other.data)), nil
+}
+
+func (bt BytesType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	eq, err := bt.Equals(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(eq.Lit() != true), nil
+}
+
+// bytes are ordered lexicographically, like strings
+
+func (bt BytesType) compare(val valuetypes.ValueType) (int, error) {
+	other, ok := val.(BytesType)
+	if !ok {
+		return 0, valuetypes.Errorf(valuetypes.TypeError, "cannot compare type '%s' with type '%s'", bt.Type(), val.Type())
+	}
+	return bytes.Compare(bt.data, other.data), nil
+}
+
+func (bt BytesType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	c, err := bt.compare(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(c > 0), nil
+}
+
+func (bt BytesType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	c, err := bt.compare(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(c < 0), nil
+}
@@ -0,0 +1,112 @@
+package chartype
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+)
+
+// CharType is a single Unicode code point
+type CharType struct {
+	value rune
+}
+
+func New(value rune) CharType {
+	return CharType{value: value}
+}
+
+// FromCodePoint creates a char from a code point, which must be a valid one
+func FromCodePoint(cp int) (CharType, error) {
+	if !utf8.ValidRune(rune(cp)) || int(rune(cp)) != cp {
+		return CharType{}, fmt.Errorf("%d is not a valid code point", cp)
+	}
+	return New(rune(cp)), nil
+}
+
+func (ct CharType) CodePoint() int {
+	return int(ct.value)
+}
+
+func (ct CharType) Fmt() string {
+	return string(ct.value)
+}
+
+func (ct CharType) Lit() any {
+	return ct.value
+}
+
+func (ct CharType) Type() string {
+	return "char"
+}
+
+func (ct CharType) Hash() (string, bool) {
+	return "c:" + string(ct.value), true
+}
+
+func (ct CharType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(ct, "addition")
+}
+
+func (ct CharType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(ct, "concatenation")
+}
+
+func (ct CharType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(ct, "subtraction")
+}
+
+func (ct CharType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(ct, "multiplication")
+}
+
+func (ct CharType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(ct, "division")
+}
+
+func (ct CharType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(ct, "modulus")
+}
+
+func (ct CharType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(ct, "bitwise AND")
+}
+
+func (ct CharType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(ct, "bitwise OR")
+}
+
+func (ct CharType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(ct, "bitwise XOR")
+}
+
+func (ct CharType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "char" {
+		return booltype.New(false), nil
+	}
+	return booltype.New(ct.value == val.(CharType).value), nil
+}
+
+func (ct CharType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "char" {
+		return booltype.New(true), nil
+	}
+	return booltype.New(ct.value != val.(CharType).value), nil
+}
+
+// chars are ordered by code point
+
+func (ct CharType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "char" {
+		return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot compare type '%s' with type '%s'", ct.Type(), val.Type())
+	}
+	return booltype.New(ct.value > val.(CharType).value), nil
+}
+
+func (ct CharType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "char" {
+		return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot compare type '%s' with type '%s'", ct.Type(), val.Type())
+	}
+	return booltype.New(ct.value < val.(CharType).value), nil
+}
@@ -0,0 +1,50 @@
+package valuetypes
+
+import (
+	"errors"
+	"fmt"
+)
+
+// the kinds of error that Opal code can catch and test for; errors raised by Opal code can be of kinds of their own
+const (
+	GeneralError   = "error"
+	TypeError      = "type error"
+	ArityError     = "arity error"
+	IOError        = "io error"
+	DivisionByZero = "division by zero"
+)
+
+// KindError is an error of a particular kind
+type KindError struct {
+	kind, message string
+}
+
+func NewError(kind, message string) KindError {
+	return KindError{kind: kind, message: message}
+}
+
+func Errorf(kind string, format string, a ...any) error {
+	return NewError(kind, fmt.Sprintf(format, a...))
+}
+
+func (e KindError) Error() string {
+	return e.message
+}
+
+func (e KindError) Kind() string {
+	return e.kind
+}
+
+// Kind returns the kind of err, which is GeneralError unless it says otherwise
+func Kind(err error) string {
+	var k KindError
+	if errors.As(err, &k) {
+		return k.kind
+	}
+	return GeneralError
+}
+
+// Unsupported is the error for an operation that values of the type of val can't do
+func Unsupported(val ValueType, op string) error {
+	return Errorf(TypeError, "type '%s' does not support %s", val.Type(), op)
+}
@@ -1,22 +1,85 @@
 package funtype
 
 import (
-	"errors"
 	"fmt"
 	"math/rand"
 
 	"github.com/voidwyrm-2/opal/common"
 	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
-	"github.com/voidwyrm-2/opal/interpreter/valuetypes/numbertype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
 )
 
+// Variadic is the arity of functions that accept any number of arguments
+const Variadic = -1
+
+/*
+Step runs the body of a function once.
+Rather than making a call in tail position itself, it may hand it back as a TailCall,
+which Call then makes in a loop, so tail calls run in constant Go stack space.
+*/
+type Step func(args []valuetypes.ValueType) (valuetypes.ValueType, *TailCall, error)
+
+type TailCall struct {
+	Fn   FunType
+	Args []valuetypes.ValueType
+}
+
 type FunType struct {
-	value func(args []valuetypes.ValueType)
+	name  string
+	arity int
+	step  Step
+	steps bool   // whether step may return tail calls
 	hash  string // used for equality reasons
 }
 
-func New(value func(args []valuetypes.ValueType)) FunType {
-	return FunType{value: value, hash: fmt.Sprintf("%d%d%d%d%d", rand.Intn(10), rand.Intn(10), rand.Intn(10), rand.Intn(10), rand.Intn(10))}
+func New(name string, arity int, value func(args []valuetypes.ValueType) (valuetypes.ValueType, error)) FunType {
+	return FunType{name: name, arity: arity, step: func(args []valuetypes.ValueType) (valuetypes.ValueType, *TailCall, error) {
+		v, err := value(args)
+		return v, nil, err
+	}, steps: false, hash: newHash()}
+}
+
+// NewStepping creates a function whose body may return tail calls instead of making them
+func NewStepping(name string, arity int, step Step) FunType {
+	return FunType{name: name, arity: arity, step: step, steps: true, hash: newHash()}
+}
+
+func newHash() string {
+	return fmt.Sprintf("%d%d%d%d%d", rand.Intn(10), rand.Intn(10), rand.Intn(10), rand.Intn(10), rand.Intn(10))
+}
+
+func (ft FunType) Name() string {
+	return ft.name
+}
+
+func (ft FunType) Arity() int {
+	return ft.arity
+}
+
+// Steps reports whether the function was created with NewStepping
+func (ft FunType) Steps() bool {
+	return ft.steps
+}
+
+func (ft FunType) CheckArity(args []valuetypes.ValueType) error {
+	if ft.arity != Variadic && len(args) != ft.arity {
+		return valuetypes.Errorf(valuetypes.ArityError, "function '%s' expects %d argument(s), but received %d", ft.name, ft.arity, len(args))
+	}
+	return nil
+}
+
+func (ft FunType) Call(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+	for {
+		if err := ft.CheckArity(args); err != nil {
+			return nil, err
+		}
+
+		result, tail, err := ft.step(args)
+		if err != nil || tail == nil {
+			return result, err
+		}
+		ft, args = tail.Fn, tail.Args
+	}
 }
 
 func (ft FunType) Fmt() string {
@@ -24,7 +87,7 @@ func (ft FunType) Fmt() string {
 }
 
 func (ft FunType) Lit() any {
-	return ft.value
+	return ft.step
 }
 
 func (ft FunType) Type() string {
@@ -32,66 +95,66 @@ func (ft FunType) Type() string {
 }
 
 func (ft FunType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support addition")
+	return nil, valuetypes.Unsupported(ft, "addition")
 }
 
 func (ft FunType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, nil
+	return nil, valuetypes.Unsupported(ft, "concatenation")
 }
 
 func (ft FunType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support subtraction")
+	return nil, valuetypes.Unsupported(ft, "subtraction")
 }
 
 func (ft FunType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support multiplication")
+	return nil, valuetypes.Unsupported(ft, "multiplication")
 }
 
 func (ft FunType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support division")
+	return nil, valuetypes.Unsupported(ft, "division")
 }
 
 func (ft FunType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support modulus")
+	return nil, valuetypes.Unsupported(ft, "modulus")
 }
 
 func (ft FunType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support bitwise AND")
+	return nil, valuetypes.Unsupported(ft, "bitwise AND")
 }
 
 func (ft FunType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support bitwise OR")
+	return nil, valuetypes.Unsupported(ft, "bitwise OR")
 }
 
 func (ft FunType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support bitwise XOR")
+	return nil, valuetypes.Unsupported(ft, "bitwise XOR")
 }
 
 func (ft FunType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
 	if val.Type() == "fun" {
 		fn, _ := val.(FunType)
 		if ft.hash == fn.hash {
-			return numbertype.New(1), nil
+			return booltype.New(true), nil
 		}
 	}
 
-	return numbertype.New(0), nil
+	return booltype.New(false), nil
 }
 
 func (ft FunType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
 	return func() valuetypes.ValueType {
-		if common.Assert(ft.Equals(val)).Lit() == 1 {
-			return numbertype.New(0)
+		if common.Assert(ft.Equals(val)).Lit() == true {
+			return booltype.New(false)
 		} else {
-			return numbertype.New(1)
+			return booltype.New(true)
 		}
 	}(), nil
 }
 
 func (ft FunType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support greater than")
+	return nil, valuetypes.Unsupported(ft, "greater than")
 }
 
 func (ft FunType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + ft.Type() + "' does not support lesser than")
+	return nil, valuetypes.Unsupported(ft, "lesser than")
 }
@@ -2,93 +2,111 @@ package listtype
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
 )
 
-type Node struct {
-	value      valuetypes.ValueType
-	next, prev *Node
-}
-
-func NewNode(value valuetypes.ValueType, next, prev *Node) *Node {
-	return &Node{value: value, next: next, prev: prev}
+/*
+cell is one link of a list.
+Cells are never changed once they're made, so lists can share them freely;
+taking the tail of a list, or putting a value on its front, makes a new list without copying anything.
+*/
+type cell struct {
+	value valuetypes.ValueType
+	next  *cell
 }
 
 type ListType struct {
-	back, front *Node
-	length      int
+	front  *cell
+	length int
 }
 
 func New(initialValues ...valuetypes.ValueType) ListType {
-	l := ListType{back: nil, front: nil, length: 0}
-	for _, v := range initialValues {
-		l.Append(v)
+	return build(initialValues, ListType{front: nil, length: 0})
+}
+
+// build makes a list of values followed by the elements of rest, which it shares rather than copies
+func build(values []valuetypes.ValueType, rest ListType) ListType {
+	l := rest
+	for i := len(values) - 1; i >= 0; i-- {
+		l = l.Cons(values[i])
 	}
 	return l
 }
 
-func (lt *ListType) Iter(fn func(val valuetypes.ValueType) error) error {
+// Cons returns the list with value put on its front
+func (lt ListType) Cons(value valuetypes.ValueType) ListType {
+	return ListType{front: &cell{value: value, next: lt.front}, length: lt.length + 1}
+}
+
+func (lt ListType) Len() int {
+	return lt.length
+}
+
+func (lt ListType) Head() (valuetypes.ValueType, error) {
 	if lt.length == 0 {
-		return nil
+		return nil, errors.New("cannot take the head of an empty list")
 	}
+	return lt.front.value, nil
+}
 
-	current := lt.back
-	for current != nil {
-		if err := fn(current.value); err != nil {
-			return err
-		}
-		current = current.next
+func (lt ListType) Tail() (ListType, error) {
+	if lt.length == 0 {
+		return ListType{}, errors.New("cannot take the tail of an empty list")
 	}
-
-	return nil
+	return ListType{front: lt.front.next, length: lt.length - 1}, nil
 }
 
-func (lt *ListType) Map(fn func(val valuetypes.ValueType) (valuetypes.ValueType, error)) (ListType, error) {
-	mapped := New()
-	if lt.length == 0 {
-		return mapped, nil
+// Index returns the element at index i, counting from 0, or back from the end if i is negative
+func (lt ListType) Index(i int) (valuetypes.ValueType, error) {
+	pos, ok := valuetypes.Position(i, lt.length)
+	if !ok {
+		return nil, fmt.Errorf("index %d is out of range for a list of length %d", i, lt.length)
 	}
 
-	current := lt.back
-	for current != nil {
-		if v, err := fn(current.value); err != nil {
-			return ListType{}, err
-		} else {
-			mapped.Append(v)
-		}
+	current := lt.front
+	for range pos {
 		current = current.next
 	}
-
-	return mapped, nil
+	return current.value, nil
 }
 
-func (lt *ListType) Append(value valuetypes.ValueType) {
-	if lt.length == 0 {
-		lt.front = NewNode(value, nil, nil)
-		lt.back = lt.front
-	} else {
-		oldFront := lt.front
-		newFront := NewNode(value, nil, nil)
-		newFront.prev = oldFront
-		oldFront.next = newFront
-		lt.front = newFront
+// Values returns the elements of the list in order
+func (lt ListType) Values() []valuetypes.ValueType {
+	values := make([]valuetypes.ValueType, 0, lt.length)
+	for c := lt.front; c != nil; c = c.next {
+		values = append(values, c.value)
 	}
-	lt.length++
+	return values
 }
 
-func (lt *ListType) Pop() (valuetypes.ValueType, error) {
-	if lt.length == 0 {
-		return nil, errors.New("cannot empty pop from an empty list")
+func (lt ListType) Iter(fn func(val valuetypes.ValueType) error) error {
+	for c := lt.front; c != nil; c = c.next {
+		if err := fn(c.value); err != nil {
+			return err
+		}
 	}
-	lt.length--
-	popped := lt.front
-	lt.front, popped.prev = popped.prev, nil
-	if lt.length == 0 {
-		lt.back = nil
+	return nil
+}
+
+func (lt ListType) Map(fn func(val valuetypes.ValueType) (valuetypes.ValueType, error)) (ListType, error) {
+	mapped := make([]valuetypes.ValueType, 0, lt.length)
+	for c := lt.front; c != nil; c = c.next {
+		v, err := fn(c.value)
+		if err != nil {
+			return ListType{}, err
+		}
+		mapped = append(mapped, v)
 	}
-	return nil, nil
+	return New(mapped...), nil
+}
+
+// Append returns the list with value added to its end; the elements before it are copied, so lt is left as it was
+func (lt ListType) Append(value valuetypes.ValueType) ListType {
+	return build(lt.Values(), New(value))
 }
 
 func (lt ListType) Fmt() string {
@@ -115,16 +133,11 @@ func (lt ListType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
 }
 
 func (lt ListType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() == "List" {
-		l, _ := val.(ListType)
-		l.Iter(func(val valuetypes.ValueType) error {
-			lt.Append(val)
-			return nil
-		})
-	} else {
-		lt.Append(val)
+	// the right side is shared as it is, so only the left side's elements are copied
+	if l, ok := val.(ListType); ok {
+		return build(lt.Values(), l), nil
 	}
-	return lt, nil
+	return lt.Append(val), nil
 }
 
 func (lt ListType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
@@ -170,19 +183,41 @@ func (lt ListType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error
 }
 
 func (lt ListType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	panic("unimplemented")
-	return nil, nil
+	if val.Type() != "list" {
+		return booltype.New(false), nil
+	}
+
+	l, _ := val.(ListType)
+	if lt.length != l.length {
+		return booltype.New(false), nil
+	}
+
+	a, b := lt.front, l.front
+	for a != nil && a != b {
+		eq, err := a.value.Equals(b.value)
+		if err != nil {
+			return nil, err
+		} else if eq.Lit() != true {
+			return booltype.New(false), nil
+		}
+		a, b = a.next, b.next
+	}
+
+	return booltype.New(true), nil
 }
 
 func (lt ListType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	panic("unimplemented")
-	return nil, nil
+	eq, err := lt.Equals(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(eq.Lit() != true), nil
 }
 
 func (lt ListType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + lt.Type() + "' does not support greater than")
+	return nil, valuetypes.Unsupported(lt, "greater than")
 }
 
 func (lt ListType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, errors.New("type '" + lt.Type() + "' does not support lesser than")
+	return nil, valuetypes.Unsupported(lt, "lesser than")
 }
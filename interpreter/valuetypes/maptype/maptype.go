@@ -0,0 +1,316 @@
+package maptype
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+)
+
+/*
+node is a node of an AVL tree ordered by the hashes of the keys.
+Nodes are never changed once made; an update copies the nodes on the path to the key it changes,
+and shares the rest of the tree with the map it was made from.
+*/
+type node struct {
+	hash        string
+	key         valuetypes.ValueType
+	value       valuetypes.ValueType
+	left, right *node
+	height      int
+}
+
+func height(n *node) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func newNode(hash string, key valuetypes.ValueType, value valuetypes.ValueType, left, right *node) *node {
+	return &node{hash: hash, key: key, value: value, left: left, right: right, height: max(height(left), height(right)) + 1}
+}
+
+func (n *node) with(left, right *node) *node {
+	return newNode(n.hash, n.key, n.value, left, right)
+}
+
+// balance rebuilds n with the given children, rotating it if one side has become too tall
+func (n *node) balance(left, right *node) *node {
+	switch diff := height(left) - height(right); {
+	case diff > 1:
+		if height(left.left) < height(left.right) {
+			left = left.right.with(left.with(left.left, left.right.left), left.right.right)
+		}
+		return left.with(left.left, n.with(left.right, right))
+	case diff < -1:
+		if height(right.right) < height(right.left) {
+			right = right.left.with(right.left.left, right.with(right.left.right, right.right))
+		}
+		return right.with(n.with(left, right.left), right.right)
+	}
+	return n.with(left, right)
+}
+
+func (n *node) get(hash string) *node {
+	for n != nil {
+		switch {
+		case hash < n.hash:
+			n = n.left
+		case hash > n.hash:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+func (n *node) put(hash string, key valuetypes.ValueType, value valuetypes.ValueType) *node {
+	switch {
+	case n == nil:
+		return newNode(hash, key, value, nil, nil)
+	case hash < n.hash:
+		return n.balance(n.left.put(hash, key, value), n.right)
+	case hash > n.hash:
+		return n.balance(n.left, n.right.put(hash, key, value))
+	}
+	return newNode(hash, key, value, n.left, n.right)
+}
+
+// min is the node with the smallest hash under n
+func (n *node) min() *node {
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+func (n *node) delete(hash string) *node {
+	switch {
+	case n == nil:
+		return nil
+	case hash < n.hash:
+		return n.balance(n.left.delete(hash), n.right)
+	case hash > n.hash:
+		return n.balance(n.left, n.right.delete(hash))
+	case n.left == nil:
+		return n.right
+	case n.right == nil:
+		return n.left
+	}
+
+	next := n.right.min()
+	return next.balance(n.left, n.right.delete(next.hash))
+}
+
+func (n *node) iter(fn func(key, value valuetypes.ValueType) error) error {
+	if n == nil {
+		return nil
+	} else if err := n.left.iter(fn); err != nil {
+		return err
+	} else if err := fn(n.key, n.value); err != nil {
+		return err
+	}
+	return n.right.iter(fn)
+}
+
+// MapType is a persistent map; Put and Delete return a new map, and leave the one they were called on as it was
+type MapType struct {
+	root *node
+	size int
+}
+
+func New() MapType {
+	return MapType{root: nil, size: 0}
+}
+
+func (mt MapType) Len() int {
+	return mt.size
+}
+
+func hash(key valuetypes.ValueType) (string, error) {
+	h, ok := valuetypes.Hash(key)
+	if !ok {
+		return "", valuetypes.Errorf(valuetypes.TypeError, "type '%s' cannot be used as a map key", key.Type())
+	}
+	return h, nil
+}
+
+// Get returns the value for key, and whether the key is in the map
+func (mt MapType) Get(key valuetypes.ValueType) (valuetypes.ValueType, bool, error) {
+	h, err := hash(key)
+	if err != nil {
+		return nil, false, err
+	} else if n := mt.root.get(h); n != nil {
+		return n.value, true, nil
+	}
+	return nil, false, nil
+}
+
+func (mt MapType) Has(key valuetypes.ValueType) (bool, error) {
+	_, ok, err := mt.Get(key)
+	return ok, err
+}
+
+func (mt MapType) Put(key, value valuetypes.ValueType) (MapType, error) {
+	h, err := hash(key)
+	if err != nil {
+		return MapType{}, err
+	}
+
+	size := mt.size
+	if mt.root.get(h) == nil {
+		size++
+	}
+	return MapType{root: mt.root.put(h, key, value), size: size}, nil
+}
+
+func (mt MapType) Delete(key valuetypes.ValueType) (MapType, error) {
+	h, err := hash(key)
+	if err != nil {
+		return MapType{}, err
+	} else if mt.root.get(h) == nil {
+		return mt, nil
+	}
+	return MapType{root: mt.root.delete(h), size: mt.size - 1}, nil
+}
+
+// Merge returns a map with the entries of both maps, taking the value from other where they share a key
+func (mt MapType) Merge(other MapType) MapType {
+	merged := mt
+	other.root.iter(func(key, value valuetypes.ValueType) error {
+		h, _ := hash(key)
+		if merged.root.get(h) == nil {
+			merged.size++
+		}
+		merged.root = merged.root.put(h, key, value)
+		return nil
+	})
+	return merged
+}
+
+// Iter calls fn with every entry, in an order that only depends on the keys
+func (mt MapType) Iter(fn func(key, value valuetypes.ValueType) error) error {
+	return mt.root.iter(fn)
+}
+
+func (mt MapType) Keys() []valuetypes.ValueType {
+	keys := []valuetypes.ValueType{}
+	mt.Iter(func(key, _ valuetypes.ValueType) error {
+		keys = append(keys, key)
+		return nil
+	})
+	return keys
+}
+
+func (mt MapType) Values() []valuetypes.ValueType {
+	values := []valuetypes.ValueType{}
+	mt.Iter(func(_, value valuetypes.ValueType) error {
+		values = append(values, value)
+		return nil
+	})
+	return values
+}
+
+func (mt MapType) Fmt() string {
+	formatted := []string{}
+	mt.Iter(func(key, value valuetypes.ValueType) error {
+		formatted = append(formatted, key.Fmt()+": "+value.Fmt())
+		return nil
+	})
+	return "{ " + strings.Join(formatted, ", ") + " }"
+}
+
+func (mt MapType) Lit() any {
+	return mt
+}
+
+func (mt MapType) Type() string {
+	return "map"
+}
+
+func (mt MapType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "addition")
+}
+
+func (mt MapType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "concatenation")
+}
+
+func (mt MapType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "subtraction")
+}
+
+func (mt MapType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "multiplication")
+}
+
+func (mt MapType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "division")
+}
+
+func (mt MapType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "modulus")
+}
+
+func (mt MapType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "bitwise AND")
+}
+
+func (mt MapType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "bitwise OR")
+}
+
+func (mt MapType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "bitwise XOR")
+}
+
+// maps are equal when they have the same keys, with equal values
+
+func (mt MapType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, ok := val.(MapType)
+	if !ok || other.size != mt.size {
+		return booltype.New(false), nil
+	}
+
+	errUnequal := errors.New("unequal")
+	err := mt.Iter(func(key, value valuetypes.ValueType) error {
+		otherValue, ok, _ := other.Get(key)
+		if !ok {
+			return errUnequal
+		}
+
+		eq, err := value.Equals(otherValue)
+		if err != nil {
+			return err
+		} else if eq.Lit() != true {
+			return errUnequal
+		}
+		return nil
+	})
+
+	if err == errUnequal {
+		return booltype.New(false), nil
+	} else if err != nil {
+		return nil, err
+	}
+	return booltype.New(true), nil
+}
+
+func (mt MapType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	eq, err := mt.Equals(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(eq.Lit() != true), nil
+}
+
+func (mt MapType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "greater than")
+}
+
+func (mt MapType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(mt, "lesser than")
+}
@@ -1,124 +1,468 @@
 package numbertype
 
 import (
-	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
 
 	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/listtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/vectortype"
 )
 
+type kind uint8
+
+const (
+	intKind   kind = iota // fits in an int64
+	bigKind               // an integer that doesn't fit in an int64
+	ratKind               // an exact fraction that isn't a whole number
+	floatKind             // inexact
+)
+
+/*
+NumberType is a number somewhere on the numeric tower: an integer, a big integer, a rational, or a float.
+Integers grow into big integers rather than overflowing, and dividing integers that don't divide evenly gives a rational,
+so arithmetic stays exact until a float is involved; then the result is a float.
+Exact results are always stored in the smallest kind that can hold them, and the big values are never changed once made.
+*/
 type NumberType struct {
-	value float32
+	kind kind
+	i    int64
+	big  *big.Int
+	rat  *big.Rat
+	f    float64
+}
+
+func NewInt(value int64) NumberType {
+	return NumberType{kind: intKind, i: value}
+}
+
+func NewFloat(value float64) NumberType {
+	return NumberType{kind: floatKind, f: value}
+}
+
+// NewBig creates an integer of any size
+func NewBig(value *big.Int) NumberType {
+	if value.IsInt64() {
+		return NewInt(value.Int64())
+	}
+	return NumberType{kind: bigKind, big: new(big.Int).Set(value)}
+}
+
+// NewRat creates an exact fraction, which is an integer if it's whole
+func NewRat(value *big.Rat) NumberType {
+	if value.IsInt() {
+		return NewBig(value.Num())
+	}
+	return NumberType{kind: ratKind, rat: new(big.Rat).Set(value)}
+}
+
+func (nt NumberType) IsFloat() bool {
+	return nt.kind == floatKind
+}
+
+// IsInteger reports whether the number is an integer of any size
+func (nt NumberType) IsInteger() bool {
+	return nt.kind == intKind || nt.kind == bigKind
 }
 
-func New(value float32) NumberType {
-	return NumberType{value: value}
+// Int returns the number as an int64, if it's an integer that fits in one
+func (nt NumberType) Int() (int64, bool) {
+	return nt.i, nt.kind == intKind
 }
 
-func FromBool(value bool) NumberType {
-	if value {
-		return NumberType{value: 1}
+func (nt NumberType) Float() float64 {
+	switch nt.kind {
+	case intKind:
+		return float64(nt.i)
+	case bigKind:
+		f, _ := new(big.Float).SetInt(nt.big).Float64()
+		return f
+	case ratKind:
+		f, _ := nt.rat.Float64()
+		return f
 	}
-	return NumberType{value: 0}
+	return nt.f
+}
+
+func (nt NumberType) toBig() *big.Int {
+	if nt.kind == bigKind {
+		return nt.big
+	}
+	return big.NewInt(nt.i)
+}
+
+// toRat returns an exact number as a fraction
+func (nt NumberType) toRat() *big.Rat {
+	switch nt.kind {
+	case intKind:
+		return new(big.Rat).SetInt64(nt.i)
+	case bigKind:
+		return new(big.Rat).SetInt(nt.big)
+	}
+	return nt.rat
+}
+
+func (nt NumberType) IsZero() bool {
+	return nt.kind == intKind && nt.i == 0 || nt.kind == floatKind && nt.f == 0
 }
 
 func (nt NumberType) Fmt() string {
-	return fmt.Sprint(nt.value)
+	switch nt.kind {
+	case intKind:
+		return strconv.FormatInt(nt.i, 10)
+	case bigKind:
+		return nt.big.String()
+	case ratKind:
+		return nt.rat.String()
+	}
+
+	// floats always show that they're floats, even when they're whole
+	s := strconv.FormatFloat(nt.f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
 }
 
 func (nt NumberType) Lit() any {
-	return nt.value
+	switch nt.kind {
+	case bigKind:
+		return nt.big
+	case ratKind:
+		return nt.rat
+	case floatKind:
+		return nt.f
+	}
+	return nt.i
 }
 
 func (nt NumberType) Type() string {
 	return "number"
 }
 
-func (nt NumberType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
+// exact returns the number as a fraction, if it has an exact value; every finite float does
+func (nt NumberType) exact() (*big.Rat, bool) {
+	if nt.kind != floatKind {
+		return nt.toRat(), true
+	} else if math.IsInf(nt.f, 0) || math.IsNaN(nt.f) {
+		return nil, false
 	}
-	return nil, nil
+	return new(big.Rat).SetFloat64(nt.f), true
 }
 
-func (nt NumberType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
+// Hash is the same for numbers of different kinds that are equal, such as 2, 2.0 and 4/2;
+// NaN isn't equal to anything, itself included, so it can't be hashed
+func (nt NumberType) Hash() (string, bool) {
+	if nt.kind == intKind {
+		return "n:" + strconv.FormatInt(nt.i, 10), true
+	} else if r, ok := nt.exact(); ok {
+		return "n:" + NewRat(r).Fmt(), true
+	} else if math.IsNaN(nt.f) {
+		return "", false
 	}
-	return nil, nil
+	return "n:" + nt.Fmt(), true
 }
 
-func (nt NumberType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
+// unsupported is the error for an operator used with a number and a value of another type
+func (nt NumberType) unsupported(op string, val valuetypes.ValueType) error {
+	return valuetypes.Errorf(valuetypes.TypeError, "type '%s' does not support %s with type '%s'", nt.Type(), op, val.Type())
+}
+
+// ops are the ways an arithmetic operator works on each kind of number
+type ops struct {
+	name string
+	// ints is the fast path for two int64s; it reports false if the result doesn't fit in one
+	ints   func(a, b int64) (valuetypes.ValueType, bool, error)
+	exact  func(a, b *big.Rat) (valuetypes.ValueType, error)
+	floats func(a, b float64) (valuetypes.ValueType, error)
+}
+
+/*
+arith applies an arithmetic operator, using the least exact kind of the two sides.
+A list or vector on the right is worked through element-wise, with the number kept on the left of each element.
+*/
+func (nt NumberType) arith(o ops, val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	switch b := val.(type) {
+	case NumberType:
+		if nt.kind == floatKind || b.kind == floatKind {
+			return o.floats(nt.Float(), b.Float())
+		} else if nt.kind == intKind && b.kind == intKind {
+			if v, ok, err := o.ints(nt.i, b.i); ok || err != nil {
+				return v, err
+			}
+		}
+		return o.exact(nt.toRat(), b.toRat())
+	case listtype.ListType:
+		return b.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+			return nt.arith(o, elem)
+		})
+	case vectortype.VectorType:
+		return b.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+			return nt.arith(o, elem)
+		})
 	}
-	return nil, nil
+	return nil, nt.unsupported(o.name, val)
+}
+
+var errDivByZero = valuetypes.NewError(valuetypes.DivisionByZero, "division by zero")
+
+func (nt NumberType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nt.arith(ops{
+		name: "addition",
+		ints: func(a, b int64) (valuetypes.ValueType, bool, error) {
+			c := a + b
+			// it overflowed if both sides have the same sign, and the result doesn't
+			return NewInt(c), (a >= 0) != (b >= 0) || (c >= 0) == (a >= 0), nil
+		},
+		exact: func(a, b *big.Rat) (valuetypes.ValueType, error) {
+			return NewRat(new(big.Rat).Add(a, b)), nil
+		},
+		floats: func(a, b float64) (valuetypes.ValueType, error) {
+			return NewFloat(a + b), nil
+		},
+	}, val)
+}
+
+func (nt NumberType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(nt, "concatenation")
+}
+
+func (nt NumberType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nt.arith(ops{
+		name: "subtraction",
+		ints: func(a, b int64) (valuetypes.ValueType, bool, error) {
+			c := a - b
+			return NewInt(c), (a >= 0) == (b >= 0) || (c >= 0) == (a >= 0), nil
+		},
+		exact: func(a, b *big.Rat) (valuetypes.ValueType, error) {
+			return NewRat(new(big.Rat).Sub(a, b)), nil
+		},
+		floats: func(a, b float64) (valuetypes.ValueType, error) {
+			return NewFloat(a - b), nil
+		},
+	}, val)
 }
 
 func (nt NumberType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
-	}
-	return nil, nil
+	return nt.arith(ops{
+		name: "multiplication",
+		ints: func(a, b int64) (valuetypes.ValueType, bool, error) {
+			if a == 0 || b == 0 {
+				return NewInt(0), true, nil
+			}
+			c := a * b
+			return NewInt(c), c/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64), nil
+		},
+		exact: func(a, b *big.Rat) (valuetypes.ValueType, error) {
+			return NewRat(new(big.Rat).Mul(a, b)), nil
+		},
+		floats: func(a, b float64) (valuetypes.ValueType, error) {
+			return NewFloat(a * b), nil
+		},
+	}, val)
 }
 
+// Div divides exactly, so integers that don't divide evenly give a rational; IntDiv is the one that rounds
 func (nt NumberType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
+	return nt.arith(ops{
+		name: "division",
+		ints: func(a, b int64) (valuetypes.ValueType, bool, error) {
+			if b == 0 {
+				return nil, false, errDivByZero
+			}
+			return NewInt(a / b), a%b == 0 && !(a == math.MinInt64 && b == -1), nil
+		},
+		exact: func(a, b *big.Rat) (valuetypes.ValueType, error) {
+			if b.Sign() == 0 {
+				return nil, errDivByZero
+			}
+			return NewRat(new(big.Rat).Quo(a, b)), nil
+		},
+		floats: func(a, b float64) (valuetypes.ValueType, error) {
+			if b == 0 {
+				return nil, errDivByZero
+			}
+			return NewFloat(a / b), nil
+		},
+	}, val)
+}
+
+// floorRat rounds a fraction down, towards negative infinity
+func floorRat(r *big.Rat) *big.Int {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() < 0 {
+		q.Sub(q, big.NewInt(1))
 	}
-	return nil, nil
+	return q
 }
 
+// IntDiv divides and rounds down, towards negative infinity, so that `a == div [a, b] * b + a % b`
+func (nt NumberType) IntDiv(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nt.arith(ops{
+		name: "integer division",
+		ints: func(a, b int64) (valuetypes.ValueType, bool, error) {
+			if b == 0 {
+				return nil, false, errDivByZero
+			} else if a == math.MinInt64 && b == -1 {
+				return nil, false, nil
+			}
+			q := a / b
+			if (a%b != 0) && ((a < 0) != (b < 0)) {
+				q--
+			}
+			return NewInt(q), true, nil
+		},
+		exact: func(a, b *big.Rat) (valuetypes.ValueType, error) {
+			if b.Sign() == 0 {
+				return nil, errDivByZero
+			}
+			return NewBig(floorRat(new(big.Rat).Quo(a, b))), nil
+		},
+		floats: func(a, b float64) (valuetypes.ValueType, error) {
+			if b == 0 {
+				return nil, errDivByZero
+			}
+			return NewFloat(math.Floor(a / b)), nil
+		},
+	}, val)
+}
+
+// Mod takes the sign of the divisor, to match IntDiv
 func (nt NumberType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
-	}
-	return nil, nil
+	return nt.arith(ops{
+		name: "modulus",
+		ints: func(a, b int64) (valuetypes.ValueType, bool, error) {
+			if b == 0 {
+				return nil, false, errDivByZero
+			} else if b == -1 {
+				return NewInt(0), true, nil
+			}
+			m := a % b
+			if m != 0 && ((m < 0) != (b < 0)) {
+				m += b
+			}
+			return NewInt(m), true, nil
+		},
+		exact: func(a, b *big.Rat) (valuetypes.ValueType, error) {
+			if b.Sign() == 0 {
+				return nil, errDivByZero
+			}
+			q := new(big.Rat).SetInt(floorRat(new(big.Rat).Quo(a, b)))
+			return NewRat(new(big.Rat).Sub(a, q.Mul(q, b))), nil
+		},
+		floats: func(a, b float64) (valuetypes.ValueType, error) {
+			if b == 0 {
+				return nil, errDivByZero
+			}
+			m := math.Mod(a, b)
+			if m != 0 && ((m < 0) != (b < 0)) {
+				m += b
+			}
+			return NewFloat(m), nil
+		},
+	}, val)
+}
+
+// bitwise applies a bitwise operator, which is only defined for integers
+func (nt NumberType) bitwise(name string, val valuetypes.ValueType, small func(a, b int64) int64, large func(z, a, b *big.Int) *big.Int) (valuetypes.ValueType, error) {
+	notInts := valuetypes.NewError(valuetypes.TypeError, name+" is only defined for integers")
+	return nt.arith(ops{
+		name: name,
+		ints: func(a, b int64) (valuetypes.ValueType, bool, error) {
+			return NewInt(small(a, b)), true, nil
+		},
+		exact: func(a, b *big.Rat) (valuetypes.ValueType, error) {
+			if !a.IsInt() || !b.IsInt() {
+				return nil, notInts
+			}
+			return NewBig(large(new(big.Int), a.Num(), b.Num())), nil
+		},
+		floats: func(a, b float64) (valuetypes.ValueType, error) {
+			return nil, notInts
+		},
+	}, val)
 }
 
 func (nt NumberType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
-	}
-	return nil, nil
+	return nt.bitwise("bitwise AND", val, func(a, b int64) int64 {
+		return a & b
+	}, (*big.Int).And)
 }
 
 func (nt NumberType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
-	}
-	return nil, nil
+	return nt.bitwise("bitwise OR", val, func(a, b int64) int64 {
+		return a | b
+	}, (*big.Int).Or)
 }
 
 func (nt NumberType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
+	return nt.bitwise("bitwise XOR", val, func(a, b int64) int64 {
+		return a ^ b
+	}, (*big.Int).Xor)
+}
+
+// compare returns -1, 0 or 1 as nt is less than, equal to or greater than b; exact numbers are compared exactly
+func (nt NumberType) compare(b NumberType) int {
+	if nt.kind == intKind && b.kind == intKind {
+		switch {
+		case nt.i < b.i:
+			return -1
+		case nt.i > b.i:
+			return 1
+		}
+		return 0
+	}
+
+	// a float is compared exactly with an exact number, so equality agrees with Hash
+	if nt.kind != floatKind || b.kind != floatKind {
+		ra, aok := nt.exact()
+		rb, bok := b.exact()
+		if aok && bok {
+			return ra.Cmp(rb)
+		}
+	}
+
+	switch a, b := nt.Float(), b.Float(); {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
 	}
-	return nil, nil
+	return 0
 }
 
+// numbers are equal when their values are, whatever kind of number they are
+
 func (nt NumberType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	return nil, nil
+	b, ok := val.(NumberType)
+	if !ok {
+		return booltype.New(false), nil
+	} else if math.IsNaN(nt.f) || math.IsNaN(b.f) {
+		return booltype.New(false), nil
+	}
+	return booltype.New(nt.compare(b) == 0), nil
 }
 
 func (nt NumberType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
-	}
-	return nil, nil
+	eq, _ := nt.Equals(val)
+	return booltype.New(!eq.Lit().(bool)), nil
 }
 
 func (nt NumberType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
+	b, ok := val.(NumberType)
+	if !ok {
+		return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot compare type '%s' with type '%s'", nt.Type(), val.Type())
 	}
-	b := val.(NumberType)
-	return FromBool(nt.value > b.value), nil
+	return booltype.New(nt.compare(b) > 0), nil
 }
 
 func (nt NumberType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
-	if val.Type() != "number" {
-		return val.Add(nt)
+	b, ok := val.(NumberType)
+	if !ok {
+		return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot compare type '%s' with type '%s'", nt.Type(), val.Type())
 	}
-	b := val.(NumberType)
-	return FromBool(nt.value < b.value), nil
+	return booltype.New(nt.compare(b) < 0), nil
 }
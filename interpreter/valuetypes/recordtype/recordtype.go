@@ -0,0 +1,231 @@
+package recordtype
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/numbertype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/stringtype"
+)
+
+/*
+Shape is a record type declared with `type name = {fields}`,
+or one of the variants of a tagged union declared with `type union = name [fields] | ...`.
+*/
+type Shape struct {
+	name   string
+	union  string // the union the shape is a variant of, if it is one
+	fields []string
+}
+
+func NewShape(name string, fields []string) Shape {
+	return Shape{name: name, union: "", fields: fields}
+}
+
+func NewVariant(union, name string, fields []string) Shape {
+	return Shape{name: name, union: union, fields: fields}
+}
+
+func (s Shape) Name() string {
+	return s.name
+}
+
+func (s Shape) Union() (string, bool) {
+	return s.union, s.union != ""
+}
+
+func (s Shape) Fields() []string {
+	return s.fields
+}
+
+// SameAs reports whether s and other are the same record type, or the same variant of the same union
+func (s Shape) SameAs(other Shape) bool {
+	return s.name == other.name && s.union == other.union && slices.Equal(s.fields, other.fields)
+}
+
+// RecordType is a value of a record type, with a value for each of the fields of its shape, in the same order
+type RecordType struct {
+	shape  Shape
+	values []valuetypes.ValueType
+}
+
+func New(shape Shape, values []valuetypes.ValueType) RecordType {
+	return RecordType{shape: shape, values: values}
+}
+
+/*
+option is the union `type option = some [value] | none`, which is built in,
+so builtins can return `some [x]` for results that are there, and `none` for those that aren't.
+*/
+var (
+	someShape = NewVariant("option", "some", []string{"value"})
+	noneShape = NewVariant("option", "none", []string{})
+)
+
+func Some(value valuetypes.ValueType) RecordType {
+	return New(someShape, []valuetypes.ValueType{value})
+}
+
+func None() RecordType {
+	return New(noneShape, []valuetypes.ValueType{})
+}
+
+// Option returns the value held by `some [value]`, or false if rt is `none`, or isn't an option at all
+func (rt RecordType) Option() (valuetypes.ValueType, bool) {
+	if !rt.shape.SameAs(someShape) {
+		return nil, false
+	}
+	return rt.values[0], true
+}
+
+// errorShape is the record type `type error = {message, kind, line, column}`, which is built in, so a caught error can be used like any other value
+var errorShape = NewShape("error", []string{"message", "kind", "line", "column"})
+
+// NewError makes an error value; a line of 0 means where the error happened isn't known
+func NewError(message, kind string, line, column int) RecordType {
+	return New(errorShape, []valuetypes.ValueType{
+		stringtype.New(message),
+		stringtype.New(kind),
+		numbertype.NewInt(int64(line)),
+		numbertype.NewInt(int64(column)),
+	})
+}
+
+// Builtins are the shapes that are built in, by the names patterns use for them
+func Builtins() map[string]Shape {
+	return map[string]Shape{"some": someShape, "none": noneShape, "error": errorShape}
+}
+
+func (rt RecordType) IsError() bool {
+	return rt.shape.SameAs(errorShape)
+}
+
+func (rt RecordType) Shape() Shape {
+	return rt.shape
+}
+
+// Field returns the value of the named field, and whether the record has it
+func (rt RecordType) Field(name string) (valuetypes.ValueType, bool) {
+	i := slices.Index(rt.shape.fields, name)
+	if i == -1 {
+		return nil, false
+	}
+	return rt.values[i], true
+}
+
+// Values are the values of the fields, in the order they were declared
+func (rt RecordType) Values() []valuetypes.ValueType {
+	return rt.values
+}
+
+func (rt RecordType) Fmt() string {
+	if rt.shape.union != "" && len(rt.values) == 0 {
+		return rt.shape.name
+	}
+
+	formatted := []string{}
+	for i, f := range rt.shape.fields {
+		formatted = append(formatted, f+": "+rt.values[i].Fmt())
+	}
+	return rt.shape.name + " { " + strings.Join(formatted, ", ") + " }"
+}
+
+func (rt RecordType) Lit() any {
+	return rt
+}
+
+// Type is the name the record type was declared with, or the name of its union if it's a variant
+func (rt RecordType) Type() string {
+	if rt.shape.union != "" {
+		return rt.shape.union
+	}
+	return rt.shape.name
+}
+
+// Hash lets a record be a map key when all of its fields can be
+func (rt RecordType) Hash() (string, bool) {
+	hashes := []string{}
+	for _, v := range rt.values {
+		h, ok := valuetypes.Hash(v)
+		if !ok {
+			return "", false
+		}
+		// the length keeps the fields apart, whatever characters their hashes contain
+		hashes = append(hashes, strconv.Itoa(len(h))+":"+h)
+	}
+	return "r:" + rt.shape.union + "." + rt.shape.name + "{" + strings.Join(rt.shape.fields, ",") + "}" + strings.Join(hashes, ""), true
+}
+
+func (rt RecordType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "addition")
+}
+
+func (rt RecordType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "concatenation")
+}
+
+func (rt RecordType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "subtraction")
+}
+
+func (rt RecordType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "multiplication")
+}
+
+func (rt RecordType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "division")
+}
+
+func (rt RecordType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "modulus")
+}
+
+func (rt RecordType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "bitwise AND")
+}
+
+func (rt RecordType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "bitwise OR")
+}
+
+func (rt RecordType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "bitwise XOR")
+}
+
+// records are equal when they have the same shape and their fields are equal
+
+func (rt RecordType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, ok := val.(RecordType)
+	if !ok || !rt.shape.SameAs(other.shape) {
+		return booltype.New(false), nil
+	}
+
+	for i := range rt.values {
+		eq, err := rt.values[i].Equals(other.values[i])
+		if err != nil {
+			return nil, err
+		} else if eq.Lit() != true {
+			return booltype.New(false), nil
+		}
+	}
+	return booltype.New(true), nil
+}
+
+func (rt RecordType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	eq, err := rt.Equals(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(eq.Lit() != true), nil
+}
+
+func (rt RecordType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "greater than")
+}
+
+func (rt RecordType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(rt, "lesser than")
+}
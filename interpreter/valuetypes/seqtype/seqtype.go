@@ -0,0 +1,312 @@
+package seqtype
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+)
+
+// Next makes the next element of a sequence, reporting false once there are no more
+type Next func() (valuetypes.ValueType, bool, error)
+
+/*
+node is one link of a sequence, which isn't made until it's needed.
+Forcing a node calls next once, and keeps what it made, so a sequence always has the same elements however often it's walked;
+since a node can only be reached by forcing the one before it, next is always called in order.
+*/
+type node struct {
+	next   Next
+	forced bool
+	empty  bool
+	value  valuetypes.ValueType
+	rest   *node
+	err    error
+}
+
+func (n *node) force() error {
+	if n.forced {
+		return n.err
+	}
+	n.forced = true
+
+	value, ok, err := n.next()
+	if err != nil {
+		n.err = err
+	} else if !ok {
+		n.empty = true
+	} else {
+		n.value = value
+		n.rest = &node{next: n.next}
+	}
+	n.next = nil
+	return n.err
+}
+
+// SeqType is a lazy sequence; finite is false for sequences that might never end, which can't be measured or compared
+type SeqType struct {
+	front  *node
+	finite bool
+}
+
+func New(next Next, finite bool) SeqType {
+	return SeqType{front: &node{next: next}, finite: finite}
+}
+
+// Of makes a finite sequence of values that are already known
+func Of(values ...valuetypes.ValueType) SeqType {
+	i := 0
+	return New(func() (valuetypes.ValueType, bool, error) {
+		if i == len(values) {
+			return nil, false, nil
+		}
+		i++
+		return values[i-1], true, nil
+	}, true)
+}
+
+func (st SeqType) Finite() bool {
+	return st.finite
+}
+
+// Empty reports whether the sequence has no elements, making its first one if it has to
+func (st SeqType) Empty() (bool, error) {
+	if err := st.front.force(); err != nil {
+		return false, err
+	}
+	return st.front.empty, nil
+}
+
+func (st SeqType) Head() (valuetypes.ValueType, error) {
+	if empty, err := st.Empty(); err != nil {
+		return nil, err
+	} else if empty {
+		return nil, errors.New("cannot take the head of an empty sequence")
+	}
+	return st.front.value, nil
+}
+
+func (st SeqType) Tail() (SeqType, error) {
+	if empty, err := st.Empty(); err != nil {
+		return SeqType{}, err
+	} else if empty {
+		return SeqType{}, errors.New("cannot take the tail of an empty sequence")
+	}
+	return SeqType{front: st.front.rest, finite: st.finite}, nil
+}
+
+// Take returns up to n elements from the front of the sequence
+func (st SeqType) Take(n int) ([]valuetypes.ValueType, error) {
+	values := []valuetypes.ValueType{}
+	for current := st.front; len(values) < n; current = current.rest {
+		if err := current.force(); err != nil {
+			return nil, err
+		} else if current.empty {
+			break
+		}
+		values = append(values, current.value)
+	}
+	return values, nil
+}
+
+// Values returns every element of a finite sequence
+func (st SeqType) Values() ([]valuetypes.ValueType, error) {
+	if !st.finite {
+		return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot take every element of an infinite sequence")
+	}
+
+	values := []valuetypes.ValueType{}
+	for current := st.front; ; current = current.rest {
+		if err := current.force(); err != nil {
+			return nil, err
+		} else if current.empty {
+			return values, nil
+		}
+		values = append(values, current.value)
+	}
+}
+
+// Len counts the elements of a finite sequence, making all of them but only keeping hold of the one it's up to
+func (st SeqType) Len() (int, error) {
+	if !st.finite {
+		return 0, valuetypes.Errorf(valuetypes.TypeError, "cannot take the length of an infinite sequence")
+	}
+
+	n := 0
+	for current := st.front; ; current = current.rest {
+		if err := current.force(); err != nil {
+			return 0, err
+		} else if current.empty {
+			return n, nil
+		}
+		n++
+	}
+}
+
+// Map returns a sequence of fn applied to each element, which is only applied as the elements are needed
+func (st SeqType) Map(fn func(val valuetypes.ValueType) (valuetypes.ValueType, error)) SeqType {
+	current := st
+	return New(func() (valuetypes.ValueType, bool, error) {
+		if empty, err := current.Empty(); err != nil || empty {
+			return nil, false, err
+		}
+
+		value := current.front.value
+		current = SeqType{front: current.front.rest, finite: current.finite}
+
+		mapped, err := fn(value)
+		if err != nil {
+			return nil, false, err
+		}
+		return mapped, true, nil
+	}, st.finite)
+}
+
+// Filter returns a sequence of the elements that keep reports true for, which are only found as they're needed
+func (st SeqType) Filter(keep func(val valuetypes.ValueType) (bool, error)) SeqType {
+	current := st
+	return New(func() (valuetypes.ValueType, bool, error) {
+		for {
+			if empty, err := current.Empty(); err != nil || empty {
+				return nil, false, err
+			}
+
+			value := current.front.value
+			current = SeqType{front: current.front.rest, finite: current.finite}
+
+			if ok, err := keep(value); err != nil {
+				return nil, false, err
+			} else if ok {
+				return value, true, nil
+			}
+		}
+	}, st.finite)
+}
+
+// Fmt shows the first few elements of the sequence, without making any more than that
+func (st SeqType) Fmt() string {
+	const shown = 10
+
+	values, err := st.Take(shown + 1)
+	if err != nil {
+		return "seq [ ... ]"
+	}
+
+	formatted := []string{}
+	for i, v := range values {
+		if i == shown {
+			formatted = append(formatted, "...")
+			break
+		}
+		formatted = append(formatted, v.Fmt())
+	}
+	return "seq [ " + strings.Join(formatted, ", ") + " ]"
+}
+
+func (st SeqType) Lit() any {
+	return st
+}
+
+func (st SeqType) Type() string {
+	return "sequence"
+}
+
+func (st SeqType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return st.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Add(val)
+	}), nil
+}
+
+func (st SeqType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "concatenation")
+}
+
+func (st SeqType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return st.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Sub(val)
+	}), nil
+}
+
+func (st SeqType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return st.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Mul(val)
+	}), nil
+}
+
+func (st SeqType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return st.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Div(val)
+	}), nil
+}
+
+func (st SeqType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return st.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Mod(val)
+	}), nil
+}
+
+func (st SeqType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return st.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.BitAnd(val)
+	}), nil
+}
+
+func (st SeqType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return st.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.BitOr(val)
+	}), nil
+}
+
+func (st SeqType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return st.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.BitXOR(val)
+	}), nil
+}
+
+// Equals compares finite sequences element by element; infinite ones might never finish, so they can't be compared
+func (st SeqType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, ok := val.(SeqType)
+	if !ok {
+		return booltype.New(false), nil
+	} else if !st.finite || !other.finite {
+		return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot compare infinite sequences")
+	}
+
+	a, err := st.Values()
+	if err != nil {
+		return nil, err
+	}
+	b, err := other.Values()
+	if err != nil {
+		return nil, err
+	} else if len(a) != len(b) {
+		return booltype.New(false), nil
+	}
+
+	for i := range a {
+		eq, err := a[i].Equals(b[i])
+		if err != nil {
+			return nil, err
+		} else if eq.Lit() != true {
+			return booltype.New(false), nil
+		}
+	}
+	return booltype.New(true), nil
+}
+
+func (st SeqType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	eq, err := st.Equals(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(eq.Lit() != true), nil
+}
+
+func (st SeqType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "greater than")
+}
+
+func (st SeqType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "lesser than")
+}
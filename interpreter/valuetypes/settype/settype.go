@@ -0,0 +1,231 @@
+package settype
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/maptype"
+)
+
+/*
+SetType is a persistent set, kept as a map from each element to itself, so it shares the map's tree:
+Insert and Remove return a new set, and leave the one they were called on as it was.
+Elements are told apart by their hashes, so only values that can be map keys can be in a set.
+*/
+type SetType struct {
+	elems maptype.MapType
+}
+
+func New(values ...valuetypes.ValueType) (SetType, error) {
+	st := SetType{elems: maptype.New()}
+	for _, v := range values {
+		var err error
+		if st, err = st.Insert(v); err != nil {
+			return SetType{}, err
+		}
+	}
+	return st, nil
+}
+
+func (st SetType) Len() int {
+	return st.elems.Len()
+}
+
+// element checks that val can be in a set, so the error doesn't talk about the map the set is kept in
+func element(val valuetypes.ValueType) error {
+	if _, ok := valuetypes.Hash(val); !ok {
+		return valuetypes.Errorf(valuetypes.TypeError, "type '%s' cannot be an element of a set", val.Type())
+	}
+	return nil
+}
+
+func (st SetType) Has(val valuetypes.ValueType) (bool, error) {
+	if err := element(val); err != nil {
+		return false, err
+	}
+	return st.elems.Has(val)
+}
+
+func (st SetType) Insert(val valuetypes.ValueType) (SetType, error) {
+	if err := element(val); err != nil {
+		return SetType{}, err
+	}
+	elems, err := st.elems.Put(val, val)
+	if err != nil {
+		return SetType{}, err
+	}
+	return SetType{elems: elems}, nil
+}
+
+func (st SetType) Remove(val valuetypes.ValueType) (SetType, error) {
+	if err := element(val); err != nil {
+		return SetType{}, err
+	}
+	elems, err := st.elems.Delete(val)
+	if err != nil {
+		return SetType{}, err
+	}
+	return SetType{elems: elems}, nil
+}
+
+// Values returns the elements of the set, in an order that only depends on what they are
+func (st SetType) Values() []valuetypes.ValueType {
+	return st.elems.Keys()
+}
+
+func (st SetType) Union(other SetType) SetType {
+	if other.Len() > st.Len() {
+		st, other = other, st
+	}
+	return SetType{elems: st.elems.Merge(other.elems)}
+}
+
+func (st SetType) Intersection(other SetType) SetType {
+	if other.Len() < st.Len() {
+		st, other = other, st
+	}
+	return st.keep(other, true)
+}
+
+// Difference is the elements of st that aren't in other
+func (st SetType) Difference(other SetType) SetType {
+	return st.keep(other, false)
+}
+
+// SymmetricDifference is the elements that are in one of the sets, but not both
+func (st SetType) SymmetricDifference(other SetType) SetType {
+	return st.Difference(other).Union(other.Difference(st))
+}
+
+// keep returns the elements of st that are in other, or the ones that aren't if in is false
+func (st SetType) keep(other SetType, in bool) SetType {
+	kept := SetType{elems: maptype.New()}
+	for _, v := range st.Values() {
+		// every element of a set can be hashed, so neither of these can fail
+		if ok, _ := other.Has(v); ok == in {
+			kept, _ = kept.Insert(v)
+		}
+	}
+	return kept
+}
+
+// operand returns the right side of a set operator, which has to be another set
+func (st SetType) operand(op string, val valuetypes.ValueType) (SetType, error) {
+	other, ok := val.(SetType)
+	if !ok {
+		return SetType{}, valuetypes.Errorf(valuetypes.TypeError, "cannot take the %s of type '%s' and type '%s'", op, st.Type(), val.Type())
+	}
+	return other, nil
+}
+
+func (st SetType) Fmt() string {
+	formatted := []string{}
+	for _, v := range st.Values() {
+		formatted = append(formatted, v.Fmt())
+	}
+	return "#{ " + strings.Join(formatted, ", ") + " }"
+}
+
+func (st SetType) Lit() any {
+	return st
+}
+
+func (st SetType) Type() string {
+	return "set"
+}
+
+// a set can always be a map key, or an element of another set, since all of its elements can
+
+func (st SetType) Hash() (string, bool) {
+	hashes := []string{}
+	for _, v := range st.Values() {
+		h, _ := valuetypes.Hash(v)
+		hashes = append(hashes, strconv.Itoa(len(h))+":"+h)
+	}
+	return "set:" + strconv.Itoa(st.Len()) + ":" + strings.Join(hashes, ""), true
+}
+
+func (st SetType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "addition")
+}
+
+func (st SetType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "concatenation")
+}
+
+func (st SetType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, err := st.operand("difference", val)
+	if err != nil {
+		return nil, err
+	}
+	return st.Difference(other), nil
+}
+
+func (st SetType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "multiplication")
+}
+
+func (st SetType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "division")
+}
+
+func (st SetType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "modulus")
+}
+
+// the bitwise operators are the set operations they're named after: `|` is union, `&` intersection, and `^` symmetric difference
+
+func (st SetType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, err := st.operand("intersection", val)
+	if err != nil {
+		return nil, err
+	}
+	return st.Intersection(other), nil
+}
+
+func (st SetType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, err := st.operand("union", val)
+	if err != nil {
+		return nil, err
+	}
+	return st.Union(other), nil
+}
+
+func (st SetType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, err := st.operand("symmetric difference", val)
+	if err != nil {
+		return nil, err
+	}
+	return st.SymmetricDifference(other), nil
+}
+
+// sets are equal when they have the same elements, so their hashes are equal too
+
+func (st SetType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, ok := val.(SetType)
+	if !ok || other.Len() != st.Len() {
+		return booltype.New(false), nil
+	}
+
+	a, _ := st.Hash()
+	b, _ := other.Hash()
+	return booltype.New(a == b), nil
+}
+
+func (st SetType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	eq, err := st.Equals(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(eq.Lit() != true), nil
+}
+
+func (st SetType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "greater than")
+}
+
+func (st SetType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "lesser than")
+}
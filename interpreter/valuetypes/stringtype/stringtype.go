@@ -0,0 +1,148 @@
+package stringtype
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/chartype"
+)
+
+// StringType keeps its length in characters, so measuring a string, or the tail of one, doesn't have to count them again
+type StringType struct {
+	value  string
+	length int
+}
+
+func New(value string) StringType {
+	return StringType{value: value, length: utf8.RuneCountInString(value)}
+}
+
+// Len is the length of the string in characters, rather than bytes
+func (st StringType) Len() int {
+	return st.length
+}
+
+func (st StringType) Empty() bool {
+	return st.value == ""
+}
+
+func (st StringType) Head() (chartype.CharType, error) {
+	if st.value == "" {
+		return chartype.CharType{}, errors.New("cannot take the head of an empty string")
+	}
+	r, _ := utf8.DecodeRuneInString(st.value)
+	return chartype.New(r), nil
+}
+
+func (st StringType) Tail() (StringType, error) {
+	if st.value == "" {
+		return StringType{}, errors.New("cannot take the tail of an empty string")
+	}
+	_, size := utf8.DecodeRuneInString(st.value)
+	return StringType{value: st.value[size:], length: st.length - 1}, nil
+}
+
+// Index returns the character at index i, counting in characters from 0, or back from the end if i is negative
+func (st StringType) Index(i int) (valuetypes.ValueType, error) {
+	pos, ok := valuetypes.Position(i, st.Len())
+	if !ok {
+		return nil, fmt.Errorf("index %d is out of range for a string of length %d", i, st.Len())
+	}
+
+	n := 0
+	for _, r := range st.value {
+		if n == pos {
+			return chartype.New(r), nil
+		}
+		n++
+	}
+	return nil, fmt.Errorf("index %d is out of range for a string of length %d", i, st.Len())
+}
+
+func (st StringType) Fmt() string {
+	return st.value
+}
+
+func (st StringType) Lit() any {
+	return st.value
+}
+
+func (st StringType) Type() string {
+	return "string"
+}
+
+func (st StringType) Hash() (string, bool) {
+	return "s:" + st.value, true
+}
+
+func (st StringType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "addition")
+}
+
+func (st StringType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "string" {
+		return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot concatenate type '%s' with type '%s'", st.Type(), val.Type())
+	}
+	return New(st.value + val.(StringType).value), nil
+}
+
+func (st StringType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "subtraction")
+}
+
+func (st StringType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "multiplication")
+}
+
+func (st StringType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "division")
+}
+
+func (st StringType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "modulus")
+}
+
+func (st StringType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "bitwise AND")
+}
+
+func (st StringType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "bitwise OR")
+}
+
+func (st StringType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(st, "bitwise XOR")
+}
+
+func (st StringType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "string" {
+		return booltype.New(false), nil
+	}
+	return booltype.New(st.value == val.(StringType).value), nil
+}
+
+func (st StringType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "string" {
+		return booltype.New(true), nil
+	}
+	return booltype.New(st.value != val.(StringType).value), nil
+}
+
+// strings are ordered lexicographically by code point, which is the same as comparing their UTF-8 bytes
+
+func (st StringType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "string" {
+		return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot compare type '%s' with type '%s'", st.Type(), val.Type())
+	}
+	return booltype.New(st.value > val.(StringType).value), nil
+}
+
+func (st StringType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if val.Type() != "string" {
+		return nil, valuetypes.Errorf(valuetypes.TypeError, "cannot compare type '%s' with type '%s'", st.Type(), val.Type())
+	}
+	return booltype.New(st.value < val.(StringType).value), nil
+}
@@ -0,0 +1,178 @@
+package tupletype
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+)
+
+// TupleType is a fixed number of values, written `(a, b)`; the empty tuple `()` is the unit value, for results that don't mean anything
+type TupleType struct {
+	values []valuetypes.ValueType
+}
+
+func New(values ...valuetypes.ValueType) TupleType {
+	return TupleType{values: append([]valuetypes.ValueType{}, values...)}
+}
+
+func Unit() TupleType {
+	return TupleType{values: []valuetypes.ValueType{}}
+}
+
+func (tt TupleType) IsUnit() bool {
+	return len(tt.values) == 0
+}
+
+func (tt TupleType) Len() int {
+	return len(tt.values)
+}
+
+// Values returns the elements of the tuple in order
+func (tt TupleType) Values() []valuetypes.ValueType {
+	return append([]valuetypes.ValueType{}, tt.values...)
+}
+
+func (tt TupleType) Fmt() string {
+	formatted := []string{}
+	for _, v := range tt.values {
+		formatted = append(formatted, v.Fmt())
+	}
+	if len(formatted) == 1 {
+		return "(" + formatted[0] + ",)"
+	}
+	return "(" + strings.Join(formatted, ", ") + ")"
+}
+
+func (tt TupleType) Lit() any {
+	return tt
+}
+
+func (tt TupleType) Type() string {
+	if tt.IsUnit() {
+		return "unit"
+	}
+	return "tuple"
+}
+
+// a tuple can be a map key if all of its elements can
+
+func (tt TupleType) Hash() (string, bool) {
+	hashes := []string{}
+	for _, v := range tt.values {
+		h, ok := valuetypes.Hash(v)
+		if !ok {
+			return "", false
+		}
+		hashes = append(hashes, strconv.Itoa(len(h))+":"+h)
+	}
+	return "t:" + strconv.Itoa(len(tt.values)) + ":" + strings.Join(hashes, ""), true
+}
+
+func (tt TupleType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(tt, "addition")
+}
+
+func (tt TupleType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(tt, "concatenation")
+}
+
+func (tt TupleType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(tt, "subtraction")
+}
+
+func (tt TupleType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(tt, "multiplication")
+}
+
+func (tt TupleType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(tt, "division")
+}
+
+func (tt TupleType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(tt, "modulus")
+}
+
+func (tt TupleType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(tt, "bitwise AND")
+}
+
+func (tt TupleType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(tt, "bitwise OR")
+}
+
+func (tt TupleType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(tt, "bitwise XOR")
+}
+
+func (tt TupleType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	other, ok := val.(TupleType)
+	if !ok || len(tt.values) != len(other.values) {
+		return booltype.New(false), nil
+	}
+
+	for i := range tt.values {
+		eq, err := tt.values[i].Equals(other.values[i])
+		if err != nil {
+			return nil, err
+		} else if eq.Lit() != true {
+			return booltype.New(false), nil
+		}
+	}
+	return booltype.New(true), nil
+}
+
+func (tt TupleType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	eq, err := tt.Equals(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(eq.Lit() != true), nil
+}
+
+/*
+compare orders tuples lexicographically: by their first elements that aren't equal,
+or, if one tuple starts with all of the other's elements, by their lengths.
+*/
+func (tt TupleType) compare(val valuetypes.ValueType) (int, error) {
+	other, ok := val.(TupleType)
+	if !ok {
+		return 0, valuetypes.Errorf(valuetypes.TypeError, "cannot compare type '%s' with type '%s'", tt.Type(), val.Type())
+	}
+
+	for i := range min(len(tt.values), len(other.values)) {
+		a, b := tt.values[i], other.values[i]
+		if eq, err := a.Equals(b); err != nil {
+			return 0, err
+		} else if eq.Lit() == true {
+			continue
+		}
+
+		lt, err := a.LesserThan(b)
+		if err != nil {
+			return 0, err
+		} else if lt.Lit() == true {
+			return -1, nil
+		}
+		return 1, nil
+	}
+
+	return len(tt.values) - len(other.values), nil
+}
+
+func (tt TupleType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	c, err := tt.compare(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(c > 0), nil
+}
+
+func (tt TupleType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	c, err := tt.compare(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(c < 0), nil
+}
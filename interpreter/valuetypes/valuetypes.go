@@ -18,3 +18,38 @@ type ValueType interface {
 	GreaterThan(val ValueType) (ValueType, error)
 	LesserThan(val ValueType) (ValueType, error)
 }
+
+/*
+Hashable is a value that can be used as a map key, if Hash reports that it can;
+a record, for example, can only be a key if all of its fields can.
+Values that are equal must have the same hash, and values that aren't must not,
+so the hash includes the kind of value it came from.
+*/
+type Hashable interface {
+	ValueType
+	Hash() (string, bool)
+}
+
+// Hash returns the hash of val, if it can be used as a map key
+func Hash(val ValueType) (string, bool) {
+	if h, ok := val.(Hashable); ok {
+		return h.Hash()
+	}
+	return "", false
+}
+
+// Indexable is a value whose elements can be reached by their position, as in `xs [i]`
+type Indexable interface {
+	ValueType
+	Len() int
+	// Index returns the element at index i, counting from 0, or back from the end if i is negative
+	Index(i int) (ValueType, error)
+}
+
+// Position turns an index that may count back from the end into one that counts from 0, and reports whether it's in range
+func Position(i, length int) (int, bool) {
+	if i < 0 {
+		i += length
+	}
+	return i, i >= 0 && i < length
+}
@@ -0,0 +1,189 @@
+package vectortype
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+)
+
+/*
+VectorType is a view of part of an array that's never changed once it's made,
+so indexing takes constant time, and slices can share the array rather than copy it.
+*/
+type VectorType struct {
+	values     []valuetypes.ValueType
+	start, end int
+}
+
+func New(initialValues ...valuetypes.ValueType) VectorType {
+	values := append([]valuetypes.ValueType{}, initialValues...)
+	return VectorType{values: values, start: 0, end: len(values)}
+}
+
+func (vt VectorType) Len() int {
+	return vt.end - vt.start
+}
+
+// Index returns the element at index i, counting from 0, or back from the end if i is negative
+func (vt VectorType) Index(i int) (valuetypes.ValueType, error) {
+	pos, ok := valuetypes.Position(i, vt.Len())
+	if !ok {
+		return nil, fmt.Errorf("index %d is out of range for a vector of length %d", i, vt.Len())
+	}
+	return vt.values[vt.start+pos], nil
+}
+
+// Slice returns the elements from index start up to, but not including, index end; negative indices count back from the end
+func (vt VectorType) Slice(start, end int) (VectorType, error) {
+	from, to := start, end
+	if from < 0 {
+		from += vt.Len()
+	}
+	if to < 0 {
+		to += vt.Len()
+	}
+
+	if from < 0 || to > vt.Len() || from > to {
+		return VectorType{}, fmt.Errorf("slice %d to %d is out of range for a vector of length %d", start, end, vt.Len())
+	}
+	return VectorType{values: vt.values, start: vt.start + from, end: vt.start + to}, nil
+}
+
+func (vt VectorType) Head() (valuetypes.ValueType, error) {
+	if vt.Len() == 0 {
+		return nil, errors.New("cannot take the head of an empty vector")
+	}
+	return vt.values[vt.start], nil
+}
+
+func (vt VectorType) Tail() (VectorType, error) {
+	if vt.Len() == 0 {
+		return VectorType{}, errors.New("cannot take the tail of an empty vector")
+	}
+	return vt.Slice(1, vt.Len())
+}
+
+// Values returns the elements of the vector in order
+func (vt VectorType) Values() []valuetypes.ValueType {
+	return append([]valuetypes.ValueType{}, vt.values[vt.start:vt.end]...)
+}
+
+func (vt VectorType) Map(fn func(val valuetypes.ValueType) (valuetypes.ValueType, error)) (VectorType, error) {
+	mapped := make([]valuetypes.ValueType, 0, vt.Len())
+	for _, v := range vt.values[vt.start:vt.end] {
+		m, err := fn(v)
+		if err != nil {
+			return VectorType{}, err
+		}
+		mapped = append(mapped, m)
+	}
+	return VectorType{values: mapped, start: 0, end: len(mapped)}, nil
+}
+
+func (vt VectorType) Fmt() string {
+	formatted := []string{}
+	for _, v := range vt.values[vt.start:vt.end] {
+		formatted = append(formatted, v.Fmt())
+	}
+	return "#[ " + strings.Join(formatted, ", ") + " ]"
+}
+
+func (vt VectorType) Lit() any {
+	return vt
+}
+
+func (vt VectorType) Type() string {
+	return "vector"
+}
+
+func (vt VectorType) Add(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return vt.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Add(val)
+	})
+}
+
+// Concat joins two vectors, or adds a value to the end of one; either way the elements are copied, so vt is left as it was
+func (vt VectorType) Concat(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	if v, ok := val.(VectorType); ok {
+		return New(append(vt.Values(), v.values[v.start:v.end]...)...), nil
+	}
+	return New(append(vt.Values(), val)...), nil
+}
+
+func (vt VectorType) Sub(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return vt.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Sub(val)
+	})
+}
+
+func (vt VectorType) Mul(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return vt.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Mul(val)
+	})
+}
+
+func (vt VectorType) Div(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return vt.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Div(val)
+	})
+}
+
+func (vt VectorType) Mod(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return vt.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.Mod(val)
+	})
+}
+
+func (vt VectorType) BitAnd(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return vt.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.BitAnd(val)
+	})
+}
+
+func (vt VectorType) BitOr(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return vt.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.BitOr(val)
+	})
+}
+
+func (vt VectorType) BitXOR(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return vt.Map(func(elem valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return elem.BitXOR(val)
+	})
+}
+
+func (vt VectorType) Equals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	v, ok := val.(VectorType)
+	if !ok || vt.Len() != v.Len() {
+		return booltype.New(false), nil
+	}
+
+	for i := range vt.Len() {
+		eq, err := vt.values[vt.start+i].Equals(v.values[v.start+i])
+		if err != nil {
+			return nil, err
+		} else if eq.Lit() != true {
+			return booltype.New(false), nil
+		}
+	}
+	return booltype.New(true), nil
+}
+
+func (vt VectorType) NotEquals(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	eq, err := vt.Equals(val)
+	if err != nil {
+		return nil, err
+	}
+	return booltype.New(eq.Lit() != true), nil
+}
+
+func (vt VectorType) GreaterThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(vt, "greater than")
+}
+
+func (vt VectorType) LesserThan(val valuetypes.ValueType) (valuetypes.ValueType, error) {
+	return nil, valuetypes.Unsupported(vt, "lesser than")
+}
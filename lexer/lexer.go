@@ -2,8 +2,11 @@ package lexer
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/voidwyrm-2/opal/diagnostics"
 	"github.com/voidwyrm-2/opal/lexer/tokens"
 )
 
@@ -19,20 +22,31 @@ type Lexer struct {
 	text         string
 	idx, col, ln int
 	ch           rune
+	errs         []error
 }
 
 func New(text string) Lexer {
-	return Lexer{text: text, idx: -1, col: 0, ln: 1, ch: -1}
+	return Lexer{text: text, idx: -1, col: 0, ln: 1, ch: -1, errs: []error{}}
 }
 
-func (l Lexer) errfPos(ln, col int, format string, a ...any) error {
-	l.ln = ln
-	l.col = col
-	return l.errf(format, a...)
+// report records an error, so lexing can carry on past it
+func (l *Lexer) report(err error) {
+	l.errs = append(l.errs, err)
 }
 
-func (l Lexer) errf(format string, a ...any) error {
-	return fmt.Errorf(fmt.Sprintf("error on line %d, col %d: ", l.ln, l.col)+format, a...)
+// illegal stands in for the text from index start to the current character, which couldn't be lexed
+func (l Lexer) illegal(startIdx, start, startln int) tokens.Token {
+	return tokens.New(tokens.Illegal, l.text[startIdx:min(l.idx, len(l.text))], start, startln).WithEnd(l.col - 1)
+}
+
+// errfSpan reports an error covering the columns start to end of line ln
+func (l Lexer) errfSpan(code string, ln, start, end int, format string, a ...any) error {
+	return diagnostics.Errorf(code, ln, start, end, format, a...)
+}
+
+// errf reports an error at the current character
+func (l Lexer) errf(code string, format string, a ...any) error {
+	return l.errfSpan(code, l.ln, l.col, l.col, format, a...)
 }
 
 func (l Lexer) charTok(kind tokens.TokenType) tokens.Token {
@@ -44,23 +58,34 @@ func (l Lexer) dCharTok(kind tokens.TokenType) tokens.Token {
 }
 
 func (l *Lexer) advance() {
-	l.idx++
+	// a newline belongs to the line it ends, so the line only changes once it's passed
+	if l.ch == '\n' {
+		l.ln++
+		l.col = 0
+	}
+
+	l.idx = l.next()
 	l.col++
 	if l.idx < len(l.text) {
-		l.ch = rune(l.text[l.idx])
+		l.ch, _ = utf8.DecodeRuneInString(l.text[l.idx:])
 	} else {
 		l.ch = -1
 	}
+}
 
-	if l.ch == '\n' {
-		l.ln++
-		l.col = 1
+// next is the index of the character after the current one; columns count characters, not bytes
+func (l Lexer) next() int {
+	if l.idx < 0 || l.idx >= len(l.text) {
+		return l.idx + 1
 	}
+	_, size := utf8.DecodeRuneInString(l.text[l.idx:])
+	return l.idx + size
 }
 
 func (l Lexer) peek() rune {
-	if l.idx+1 < len(l.text) {
-		return rune(l.text[l.idx+1])
+	if next := l.next(); next < len(l.text) {
+		r, _ := utf8.DecodeRuneInString(l.text[next:])
+		return r
 	}
 	return -1
 }
@@ -79,11 +104,13 @@ func (l Lexer) isIdent() bool {
 2: address;
 else: panic
 */
-func (l *Lexer) collectNumber(kind uint8) (tokens.Token, error) {
+func (l *Lexer) collectNumber(kind uint8) tokens.Token {
+	startIdx := l.idx
 	start := l.col
 	startln := l.ln
 	s := ""
 	dot := false
+	bad := false
 
 	if kind == 2 {
 		l.advance()
@@ -96,8 +123,9 @@ func (l *Lexer) collectNumber(kind uint8) (tokens.Token, error) {
 			}
 			dot = true
 		}
-		if l.ch == '_' && dot {
-			return tokens.Empty(), l.errf("illegal character '%s'", string(l.ch))
+		if l.ch == '_' && dot && !bad {
+			l.report(l.errf(diagnostics.InvalidNumber, "illegal character '%s' in number literal", string(l.ch)))
+			bad = true
 		}
 		s += string(l.ch)
 		l.advance()
@@ -112,21 +140,27 @@ func (l *Lexer) collectNumber(kind uint8) (tokens.Token, error) {
 		panic(fmt.Sprintf("invalid kind %d", kind))
 	}
 
-	if s[0] == '_' {
+	if bad {
+		return l.illegal(startIdx, start, startln)
+	} else if s[0] == '_' {
 		if kind == 2 {
-			return tokens.Token{}, l.errfPos(start-1, startln, "number literals cannot start with underscores")
+			l.report(l.errfSpan(diagnostics.InvalidNumber, startln, start-1, l.col-1, "number literals cannot start with underscores"))
+		} else {
+			l.report(l.errfSpan(diagnostics.InvalidNumber, startln, start, l.col-1, "number literals cannot start with underscores"))
 		}
-		return tokens.Token{}, l.errfPos(start, startln, "number literals cannot start with underscores")
+		return l.illegal(startIdx, start, startln)
 	} else if s[len(s)-1] == '_' {
-		return tokens.Token{}, l.errfPos(l.idx-start, startln, "number literals cannot end with underscores")
+		l.report(l.errfSpan(diagnostics.InvalidNumber, startln, start, l.col-1, "number literals cannot end with underscores"))
+		return l.illegal(startIdx, start, startln)
 	}
 
-	return tokens.New(tkind, strings.ReplaceAll(s, "_", ""), start, startln), nil
+	return tokens.New(tkind, strings.ReplaceAll(s, "_", ""), start, startln).WithEnd(l.col - 1)
 }
 
 /*
 0: normal;
 1: funcall;
+2: argument;
 else: panic
 */
 func (l *Lexer) collectIdent(kind uint8) tokens.Token {
@@ -153,29 +187,58 @@ func (l *Lexer) collectIdent(kind uint8) tokens.Token {
 			return tokens.If
 		case "else":
 			return tokens.Else
+		case "my":
+			return tokens.My
+		case "type":
+			return tokens.Type
+		case "match":
+			return tokens.Match
+		case "try":
+			return tokens.Try
+		case "catch":
+			return tokens.Catch
+		case "and":
+			return tokens.And
+		case "or":
+			return tokens.Or
+		case "not":
+			return tokens.Not
 		default:
 			switch kind {
 			case 0:
 				return tokens.Ident
 			case 1:
 				return tokens.Funcall
+			case 2:
+				return tokens.Arg
 			default:
 				panic(fmt.Sprintf("invalid kind %d", kind))
 			}
 		}
 	}()
 
-	return tokens.New(tkind, s, start, startln)
+	return tokens.New(tkind, s, start, startln).WithEnd(l.col - 1)
+}
+
+func isHex(ch rune) bool {
+	return isNum(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
 }
 
-func (l *Lexer) collectString(isChar bool) (tokens.Token, error) {
+// collectString collects a string, a character literal, or a bytes literal, `b"..."`, which can also have `\xNN` escapes
+func (l *Lexer) collectString(kind tokens.TokenType) tokens.Token {
+	startIdx := l.idx
 	start := l.col
 	startln := l.ln
 	s := ""
 	escaped := false
+	bad := false
 
+	if kind == tokens.Bytes {
+		l.advance()
+	}
 	l.advance()
 
+	isChar := kind == tokens.Char
 	isDelimiter := func() bool {
 		if isChar {
 			return l.ch == '\''
@@ -183,7 +246,7 @@ func (l *Lexer) collectString(isChar bool) (tokens.Token, error) {
 		return l.ch == '"'
 	}
 
-	for l.ch != -1 && l.ch != '\n' && !isDelimiter() {
+	for l.ch != -1 && l.ch != '\n' && (escaped || !isDelimiter()) {
 		if escaped {
 			switch l.ch {
 			case '\\', '"', '\'':
@@ -201,8 +264,30 @@ func (l *Lexer) collectString(isChar bool) (tokens.Token, error) {
 					b[len(b)-1] = 0
 					s = string(b)
 				}
+			case 'x':
+				if kind != tokens.Bytes {
+					l.report(l.errfSpan(diagnostics.InvalidEscape, l.ln, l.col-1, l.col, "invalid escape character '%c'", l.ch))
+					bad = true
+					break
+				}
+
+				// the two digits are a byte, which doesn't have to be valid UTF-8
+				digits := ""
+				for len(digits) < 2 && isHex(l.peek()) {
+					l.advance()
+					digits += string(l.ch)
+				}
+				if len(digits) != 2 {
+					l.report(l.errfSpan(diagnostics.InvalidEscape, l.ln, l.col-1-len(digits), l.col, "'\\x' must be followed by two hexadecimal digits"))
+					bad = true
+					break
+				}
+				b, _ := strconv.ParseUint(digits, 16, 8)
+				s += string([]byte{byte(b)})
 			default:
-				return tokens.Token{}, l.errfPos(startln, start, "invalid escape character '%c'", l.ch)
+				// keep going, so every bad escape in the literal is reported
+				l.report(l.errfSpan(diagnostics.InvalidEscape, l.ln, l.col-1, l.col, "invalid escape character '%c'", l.ch))
+				bad = true
 			}
 			escaped = false
 		} else if l.ch == '\\' {
@@ -213,32 +298,78 @@ func (l *Lexer) collectString(isChar bool) (tokens.Token, error) {
 		l.advance()
 	}
 
+	// an unterminated literal stops at the end of its line, which is where lexing picks up again
 	if !isDelimiter() {
-		return tokens.Token{}, l.errfPos(startln, start, "unterminated"+func() string {
-			if isChar {
+		l.report(l.errfSpan(diagnostics.UnterminatedLiteral, startln, start, l.col-1, "unterminated "+func() string {
+			switch kind {
+			case tokens.Char:
 				return "character"
+			case tokens.Bytes:
+				return "bytes"
 			}
 			return "string"
-		}()+" literal")
-	} else if isChar && len(s) != 1 {
-		return tokens.Token{}, l.errfPos(startln, l.col-1, "invalid character literal")
+		}()+" literal"))
+		return l.illegal(startIdx, start, startln)
 	}
 
 	l.advance()
 
-	tkind := tokens.String
-	if isChar {
-		tkind = tokens.Char
+	if isChar && utf8.RuneCountInString(s) != 1 && !bad {
+		l.report(l.errfSpan(diagnostics.InvalidChar, startln, start, l.col-1, "character literals must contain exactly one character"))
+		bad = true
+	}
+	if bad {
+		return l.illegal(startIdx, start, startln)
 	}
 
-	return tokens.New(tkind, s, start, startln), nil
+	return tokens.New(kind, s, start, startln).WithEnd(l.col - 1)
 }
 
+// collectDocComment collects a `///` comment, which is kept so the checker can read function signatures from it
+func (l *Lexer) collectDocComment() tokens.Token {
+	start := l.col
+	startln := l.ln
+	s := ""
+
+	for range 3 {
+		l.advance()
+	}
+
+	for l.ch != -1 && l.ch != '\n' {
+		s += string(l.ch)
+		l.advance()
+	}
+
+	return tokens.New(tokens.DocComment, strings.TrimSpace(s), start, startln).WithEnd(l.col - 1)
+}
+
+// Lex lexes the whole text, stopping at the first error
 func (l *Lexer) Lex() ([]tokens.Token, error) {
+	toks, errs := l.LexAll()
+	if len(errs) != 0 {
+		return []tokens.Token{}, errs[0]
+	}
+	return toks, nil
+}
+
+/*
+LexAll lexes the whole text, carrying on past errors so they can all be reported at once.
+Whatever couldn't be lexed is left in the tokens as an Illegal token,
+so the tokens still cover all of the text when there are errors.
+*/
+func (l *Lexer) LexAll() ([]tokens.Token, []error) {
 	toks := []tokens.Token{}
+	l.errs = []error{}
 
 	if l.idx == -1 {
 		l.advance()
+
+		// skip the shebang line so scripts can be run directly
+		if l.ch == '#' && l.peek() == '!' {
+			for l.ch != -1 && l.ch != '\n' {
+				l.advance()
+			}
+		}
 	}
 
 	for l.ch != -1 {
@@ -252,10 +383,22 @@ func (l *Lexer) Lex() ([]tokens.Token, error) {
 			toks = append(toks, l.charTok(tokens.CloseBracket))
 			l.advance()
 		case '(':
-			toks = append(toks, l.charTok(tokens.OpenBracket))
+			toks = append(toks, l.charTok(tokens.OpenParen))
 			l.advance()
 		case ')':
-			toks = append(toks, l.charTok(tokens.CloseBracket))
+			toks = append(toks, l.charTok(tokens.CloseParen))
+			l.advance()
+		case '{':
+			toks = append(toks, l.charTok(tokens.OpenBrace))
+			l.advance()
+		case '}':
+			toks = append(toks, l.charTok(tokens.CloseBrace))
+			l.advance()
+		case ':':
+			toks = append(toks, l.charTok(tokens.Colon))
+			l.advance()
+		case ',':
+			toks = append(toks, l.charTok(tokens.Comma))
 			l.advance()
 		case '$':
 			toks = append(toks, l.charTok(tokens.Pipe))
@@ -264,19 +407,50 @@ func (l *Lexer) Lex() ([]tokens.Token, error) {
 			toks = append(toks, l.charTok(tokens.Semicolon))
 			l.advance()
 		case '+':
-			toks = append(toks, l.charTok(tokens.Plus))
+			if l.peek() == '+' {
+				toks = append(toks, l.dCharTok(tokens.Concat))
+				l.advance()
+			} else {
+				toks = append(toks, l.charTok(tokens.Plus))
+			}
 			l.advance()
 		case '-':
-			toks = append(toks, l.charTok(tokens.Hyphen))
+			if l.peek() == '>' {
+				toks = append(toks, l.dCharTok(tokens.Arrow))
+				l.advance()
+			} else {
+				toks = append(toks, l.charTok(tokens.Hyphen))
+			}
 			l.advance()
 		case '*':
 			toks = append(toks, l.charTok(tokens.Asterisk))
 			l.advance()
 		case '/':
-			if l.peek() == '/' {
+			if strings.HasPrefix(l.text[l.idx:], "///") && !strings.HasPrefix(l.text[l.idx:], "////") {
+				toks = append(toks, l.collectDocComment())
+			} else if l.peek() == '/' {
 				for l.ch != -1 && l.ch != '\n' {
 					l.advance()
 				}
+			} else if l.peek() == '*' {
+				start, startln := l.col, l.ln
+				ended := false
+
+				l.advance()
+				l.advance()
+				for l.ch != -1 {
+					if l.ch == '*' && l.peek() == '/' {
+						ended = true
+						l.advance()
+						l.advance()
+						break
+					}
+					l.advance()
+				}
+
+				if !ended {
+					l.report(l.errfSpan(diagnostics.UnterminatedComment, startln, start, start+1, "unterminated multiline comment"))
+				}
 			} else {
 				toks = append(toks, l.charTok(tokens.ForwardSlash))
 				l.advance()
@@ -292,7 +466,8 @@ func (l *Lexer) Lex() ([]tokens.Token, error) {
 				toks = append(toks, l.dCharTok(tokens.LesserThanOrEqualTo))
 				l.advance()
 			} else if l.peek() == '=' {
-				toks = append(toks, l.charTok(tokens.Equals))
+				toks = append(toks, l.dCharTok(tokens.Equals))
+				l.advance()
 			} else {
 				toks = append(toks, l.charTok(tokens.Assign))
 			}
@@ -323,67 +498,42 @@ func (l *Lexer) Lex() ([]tokens.Token, error) {
 			toks = append(toks, l.charTok(tokens.BitXOR))
 			l.advance()
 		case '"':
-			if tok, err := l.collectString(false); err != nil {
-				return []tokens.Token{}, err
-			} else {
-				toks = append(toks, tok)
-			}
+			toks = append(toks, l.collectString(tokens.String))
 		case '\'':
-			if tok, err := l.collectString(true); err != nil {
-				return []tokens.Token{}, err
-			} else {
-				toks = append(toks, tok)
-			}
+			toks = append(toks, l.collectString(tokens.Char))
 
 		default:
-			if l.ch == '/' && l.peek() == '/' {
-				for l.ch != -1 && l.ch != '\n' {
-					l.advance()
-				}
-			} else if l.ch == '/' && l.peek() == '*' {
-				start, startln := l.col, l.ln
-				ended := false
-
-				for l.ch != -1 {
-					if l.ch == '*' && l.peek() == '/' {
-						ended = true
-						break
-					}
-					l.advance()
-				}
-
-				if !ended {
-					return []tokens.Token{}, l.errfPos(startln, start, "unterminated multiline comment")
-				}
-			} else if l.ch == '!' && l.peek() == '=' {
+			if l.ch == '!' && l.peek() == '=' {
 				toks = append(toks, l.dCharTok(tokens.NotEquals))
 				l.advance()
 				l.advance()
 			} else if l.isNum() {
-				tok, err := l.collectNumber(0)
-				if err != nil {
-					return []tokens.Token{}, err
-				}
-				toks = append(toks, tok)
+				toks = append(toks, l.collectNumber(0))
 			} else if l.ch == '-' && isNum(l.peek()) {
-				tok, err := l.collectNumber(1)
-				if err != nil {
-					return []tokens.Token{}, err
-				}
-				toks = append(toks, tok)
-			} else if l.isIdent() || (l.ch == '#' && isIdent(l.peek())) {
-				if l.ch == '#' {
-					l.advance()
-				}
+				toks = append(toks, l.collectNumber(1))
+			} else if l.ch == 'b' && l.peek() == '"' {
+				toks = append(toks, l.collectString(tokens.Bytes))
+			} else if l.isIdent() {
 				toks = append(toks, l.collectIdent(0))
+			} else if l.ch == '#' && l.peek() == '[' {
+				toks = append(toks, l.dCharTok(tokens.OpenVector))
+				l.advance()
+				l.advance()
+			} else if l.ch == '#' && l.peek() == '{' {
+				toks = append(toks, l.dCharTok(tokens.OpenSet))
+				l.advance()
+				l.advance()
+			} else if l.ch == '#' && isIdent(l.peek()) {
+				toks = append(toks, l.collectIdent(2))
 			} else if l.ch == '@' {
 				toks = append(toks, l.collectIdent(1))
-				l.advance()
 			} else {
-				return []tokens.Token{}, l.errf("illegal character '%s'", string(l.ch))
+				l.report(l.errf(diagnostics.IllegalCharacter, "illegal character '%s'", string(l.ch)))
+				toks = append(toks, l.charTok(tokens.Illegal))
+				l.advance()
 			}
 		}
 	}
 
-	return toks, nil
+	return toks, l.errs
 }
@@ -1,9 +1,12 @@
 package tokens
 
 import (
-	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/diagnostics"
 )
 
 type TokenType uint8
@@ -43,6 +46,23 @@ const (
 	BitAnd
 	BitOr
 	BitXOR
+	Comma
+	My
+	Arg
+	Not
+	DocComment
+	Illegal
+	OpenBrace
+	CloseBrace
+	Colon
+	Type
+	Match
+	Arrow
+	OpenVector
+	Try
+	Catch
+	OpenSet
+	Bytes
 )
 
 func (tt TokenType) Str() string {
@@ -65,6 +85,7 @@ func (tt TokenType) Str() string {
 		"Assign",
 		"Pipe",
 		"Plus",
+		"Concat",
 		"Hyphen",
 		"Asterisk",
 		"ForwardSlash",
@@ -80,6 +101,23 @@ func (tt TokenType) Str() string {
 		"BitAnd",
 		"BitOr",
 		"BitXOR",
+		"Comma",
+		"My",
+		"Arg",
+		"Not",
+		"DocComment",
+		"Illegal",
+		"OpenBrace",
+		"CloseBrace",
+		"Colon",
+		"Type",
+		"Match",
+		"Arrow",
+		"OpenVector",
+		"Try",
+		"Catch",
+		"OpenSet",
+		"Bytes",
 	}[tt]
 }
 
@@ -87,10 +125,17 @@ type Token struct {
 	kind      TokenType
 	lit       string
 	start, ln int
+	end       int // the column of the last character of source the token covers
 }
 
 func New(kind TokenType, lit string, start, ln int) Token {
-	return Token{kind: kind, lit: lit, start: start, ln: ln}
+	return Token{kind: kind, lit: lit, start: start, ln: ln, end: start + len(lit) - 1}
+}
+
+// WithEnd sets the last column of a token whose literal isn't what was written in the source, such as a string
+func (t Token) WithEnd(end int) Token {
+	t.end = end
+	return t
 }
 
 func NewLit(kind TokenType, lit string) Token {
@@ -112,6 +157,14 @@ func (t Token) GetLit() string {
 	return t.lit
 }
 
+func (t Token) GetCol() int {
+	return t.start
+}
+
+func (t Token) GetEnd() int {
+	return max(t.end, t.start)
+}
+
 func (t Token) GetLn() int {
 	return t.ln
 }
@@ -127,10 +180,15 @@ func (t Token) IsLit(lit string) bool {
 func (t Token) Convert() (any, error) {
 	switch t.kind {
 	case Number:
-		{
-			f, err := strconv.ParseFloat(t.lit, 32)
-			return float32(f), err
+		// number literals with a decimal point are floats, and the rest are integers, which are big if they need to be
+		if strings.Contains(t.lit, ".") {
+			return strconv.ParseFloat(t.lit, 64)
+		} else if i, err := strconv.ParseInt(t.lit, 10, 64); err == nil {
+			return i, nil
+		} else if b, ok := new(big.Int).SetString(t.lit, 10); ok {
+			return b, nil
 		}
+		return nil, fmt.Errorf("invalid number literal '%s'", t.lit)
 	case String:
 		return t.lit, nil
 	case Bool:
@@ -143,6 +201,10 @@ func (t Token) Str() string {
 	return fmt.Sprintf("{%s, '%s', %d, %d}", t.kind.Str(), t.lit, t.start, t.ln)
 }
 
-func (t Token) Err(format string, a ...any) error {
-	return errors.New(fmt.Sprintf("error on line %d, col %d: ", t.ln, t.start) + fmt.Sprintf(format, a...))
+func (t Token) Err(code string, format string, a ...any) diagnostics.Diagnostic {
+	return diagnostics.Errorf(code, t.ln, t.start, t.GetEnd(), format, a...)
+}
+
+func (t Token) Warn(code string, format string, a ...any) diagnostics.Diagnostic {
+	return diagnostics.New(diagnostics.Warning, code, t.ln, t.start, t.GetEnd(), format, a...)
 }
@@ -1,45 +1,124 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 
-	"github.com/voidwyrm-2/opal/interpreter/valuetypes/listtype"
-	"github.com/voidwyrm-2/opal/interpreter/valuetypes/numbertype"
+	"github.com/voidwyrm-2/opal/checker"
+	"github.com/voidwyrm-2/opal/diagnostics"
+	"github.com/voidwyrm-2/opal/interpreter"
 	"github.com/voidwyrm-2/opal/lexer"
+	"github.com/voidwyrm-2/opal/parser"
+	"github.com/voidwyrm-2/opal/parser/nodes"
+	"github.com/voidwyrm-2/opal/repl"
 )
 
-func main() {
-	showTokens := flag.Bool("t", false, "Print the lexer tokens")
-	// showNodes := flag.Bool("n", false, "Print the parser nodes")
+var (
+	showTokens = flag.Bool("t", false, "Print the lexer tokens")
+	showNodes  = flag.Bool("n", false, "Print the parser nodes")
+	jsonOutput = flag.Bool("json", false, "Print errors as a JSON array of diagnostics, for editors")
+)
 
-	flag.Parse()
+// load lexes and parses source, returning every lexical error rather than just the first
+func load(source string) ([]nodes.Node, []error) {
+	l := lexer.New(source)
+	toks, errs := l.LexAll()
 
-	llist := listtype.New(numbertype.New(10), numbertype.New(15))
-	llist.Append(numbertype.New(20))
+	if *showTokens {
+		for _, t := range toks {
+			fmt.Println(t.Str())
+		}
+	}
 
-	fmt.Println(llist.Fmt())
+	if len(errs) != 0 {
+		return []nodes.Node{}, errs
+	}
 
-	if nl, err := llist.Concat(numbertype.New(25)); err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
-	} else {
-		fmt.Println(nl.Fmt())
+	p := parser.New(toks)
+	program, err := p.Parse()
+	if err != nil {
+		return []nodes.Node{}, []error{err}
 	}
 
-	fmt.Println(llist.Fmt())
+	if *showNodes {
+		for _, n := range program {
+			fmt.Println(n.Str())
+		}
+	}
+
+	return program, nil
+}
+
+// report prints errors, showing each with the line of source it points at
+func report(path, source string, errs []error) {
+	if *jsonOutput {
+		ds := []diagnostics.Diagnostic{}
+		for _, err := range errs {
+			ds = append(ds, diagnostics.From(err).WithFile(path))
+		}
+		b, _ := json.Marshal(ds)
+		fmt.Println(string(b))
+		return
+	}
+
+	for _, err := range errs {
+		fmt.Print(diagnostics.From(err).WithFile(path).Render(source))
+	}
+}
 
-	l := lexer.New(``)
-	toks, err := l.Lex()
+func usage() {
+	fmt.Println("usage: opal [flags] <file>\n       opal [flags] check <file>\n       opal")
+	flag.PrintDefaults()
+	os.Exit(1)
+}
+
+func main() {
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		repl.New(os.Stdout).Run(os.Stdin)
+		return
+	}
+
+	check := flag.Arg(0) == "check"
+	path := flag.Arg(0)
+	if check {
+		if flag.NArg() != 2 {
+			usage()
+		}
+		path = flag.Arg(1)
+	} else if flag.NArg() != 1 {
+		usage()
+	}
+
+	content, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Println(err.Error())
+		report(path, "", []error{err})
 		os.Exit(1)
 	}
+	source := string(content)
 
-	if *showTokens {
-		for _, t := range toks {
-			fmt.Println(t.Str())
+	program, errs := load(source)
+	if len(errs) != 0 {
+		report(path, source, errs)
+		os.Exit(1)
+	}
+
+	if check {
+		errs := checker.New().Check(program)
+		if len(errs) != 0 || *jsonOutput {
+			report(path, source, errs)
+		}
+		if diagnostics.AnyErrors(errs) {
+			os.Exit(1)
 		}
+		return
+	}
+
+	if err := interpreter.New().Run(program); err != nil {
+		report(path, source, []error{err})
+		os.Exit(1)
 	}
 }
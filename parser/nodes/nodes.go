@@ -1,8 +1,832 @@
 package nodes
 
-import "github.com/voidwyrm-2/opal/interpreter/valuetypes"
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/voidwyrm-2/opal/diagnostics"
+	"github.com/voidwyrm-2/opal/interpreter/environment"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/bytestype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/chartype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/funtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/listtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/maptype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/numbertype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/recordtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/settype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/stringtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/tupletype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/vectortype"
+	"github.com/voidwyrm-2/opal/lexer/tokens"
+)
 
 type Node interface {
-	Execute(vars map[string]valuetypes.ValueType) error
+	Execute(env *environment.Environment) (valuetypes.ValueType, error)
 	Str() string
 }
+
+func joinStr(nodes []Node) string {
+	formatted := []string{}
+	for _, n := range nodes {
+		formatted = append(formatted, n.Str())
+	}
+	return strings.Join(formatted, " ")
+}
+
+func executeAll(nodes []Node, env *environment.Environment) ([]valuetypes.ValueType, error) {
+	values := []valuetypes.ValueType{}
+	for _, n := range nodes {
+		v, err := n.Execute(env)
+		if err != nil {
+			return []valuetypes.ValueType{}, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+type Number struct {
+	Tok tokens.Token
+}
+
+func (n Number) Str() string {
+	return n.Tok.GetLit()
+}
+
+func (n Number) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	v, err := n.Tok.Convert()
+	if err != nil {
+		return nil, errAt(n.Tok, err)
+	}
+
+	switch v := v.(type) {
+	case float64:
+		return numbertype.NewFloat(v), nil
+	case *big.Int:
+		return numbertype.NewBig(v), nil
+	}
+	return numbertype.NewInt(v.(int64)), nil
+}
+
+type String struct {
+	Tok tokens.Token
+}
+
+func (n String) Str() string {
+	return strconv.Quote(n.Tok.GetLit())
+}
+
+func (n String) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	return stringtype.New(n.Tok.GetLit()), nil
+}
+
+// Bytes is `b"..."`; the literal of Tok is the bytes themselves, which needn't be valid UTF-8
+type Bytes struct {
+	Tok tokens.Token
+}
+
+func (n Bytes) Str() string {
+	return bytestype.New([]byte(n.Tok.GetLit())).Fmt()
+}
+
+func (n Bytes) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	return bytestype.New([]byte(n.Tok.GetLit())), nil
+}
+
+type Char struct {
+	Tok tokens.Token
+}
+
+func (n Char) Str() string {
+	return "'" + n.Tok.GetLit() + "'"
+}
+
+func (n Char) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	r, _ := utf8.DecodeRuneInString(n.Tok.GetLit())
+	return chartype.New(r), nil
+}
+
+type Bool struct {
+	Tok tokens.Token
+}
+
+func (n Bool) Str() string {
+	return n.Tok.GetLit()
+}
+
+func (n Bool) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	return booltype.New(n.Tok.GetLit() == "True"), nil
+}
+
+type Ident struct {
+	Tok tokens.Token
+}
+
+func (n Ident) Str() string {
+	return n.Tok.GetLit()
+}
+
+func (n Ident) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	if v, ok := env.Get(n.Tok.GetLit()); ok {
+		return v, nil
+	}
+	return nil, errf(n.Tok, "'%s' is not defined", n.Tok.GetLit())
+}
+
+// Arg is a positional argument reference such as `#1`; Index is one-based
+type Arg struct {
+	Tok   tokens.Token
+	Index int
+}
+
+func (n Arg) Str() string {
+	return "#" + n.Tok.GetLit()
+}
+
+func (n Arg) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	if v, ok := env.Arg(n.Index); ok {
+		return v, nil
+	}
+	return nil, errf(n.Tok, "argument #%d was not given", n.Index)
+}
+
+type List struct {
+	Tok   tokens.Token
+	Elems []Node
+}
+
+func (n List) Str() string {
+	return "(list " + joinStr(n.Elems) + ")"
+}
+
+func (n List) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	elems, err := executeAll(n.Elems, env)
+	if err != nil {
+		return nil, err
+	}
+	return listtype.New(elems...), nil
+}
+
+// Tuple is `(elem, ...)`; a tuple of one element is written `(elem,)`
+type Tuple struct {
+	Tok   tokens.Token
+	Elems []Node
+}
+
+func (n Tuple) Str() string {
+	return "(tuple " + joinStr(n.Elems) + ")"
+}
+
+func (n Tuple) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	elems, err := executeAll(n.Elems, env)
+	if err != nil {
+		return nil, err
+	}
+	return tupletype.New(elems...), nil
+}
+
+// Vector is `#[elem, ...]`; Tok is the opening `#[`
+type Vector struct {
+	Tok   tokens.Token
+	Elems []Node
+}
+
+func (n Vector) Str() string {
+	return "(vector " + joinStr(n.Elems) + ")"
+}
+
+func (n Vector) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	elems, err := executeAll(n.Elems, env)
+	if err != nil {
+		return nil, err
+	}
+	return vectortype.New(elems...), nil
+}
+
+// Set is `#{elem, ...}`; Tok is the opening `#{`
+type Set struct {
+	Tok   tokens.Token
+	Elems []Node
+}
+
+func (n Set) Str() string {
+	return "(set " + joinStr(n.Elems) + ")"
+}
+
+func (n Set) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	elems, err := executeAll(n.Elems, env)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := settype.New(elems...)
+	if err != nil {
+		return nil, errAt(n.Tok, err)
+	}
+	return set, nil
+}
+
+// Map is `{key: value, ...}`; Tok is the opening brace
+type Map struct {
+	Tok          tokens.Token
+	Keys, Values []Node
+}
+
+func (n Map) Str() string {
+	entries := []string{}
+	for i := range n.Keys {
+		entries = append(entries, n.Keys[i].Str()+" "+n.Values[i].Str())
+	}
+	return "(map " + strings.Join(entries, " ") + ")"
+}
+
+func (n Map) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	m := maptype.New()
+	for i := range n.Keys {
+		key, err := n.Keys[i].Execute(env)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := n.Values[i].Execute(env)
+		if err != nil {
+			return nil, err
+		}
+
+		if m, err = m.Put(key, value); err != nil {
+			return nil, errAt(n.Tok, err)
+		}
+	}
+	return m, nil
+}
+
+// Call is `name [args]`; Tok is the name
+type Call struct {
+	Tok  tokens.Token
+	Args []Node
+}
+
+func (n Call) Str() string {
+	if len(n.Args) == 0 {
+		return "(" + n.Tok.GetLit() + ")"
+	}
+	return "(" + n.Tok.GetLit() + " " + joinStr(n.Args) + ")"
+}
+
+// prepare finds the called function and executes the arguments
+func (n Call) prepare(env *environment.Environment) (tokens.Token, valuetypes.ValueType, []valuetypes.ValueType, error) {
+	fn, ok := env.Get(n.Tok.GetLit())
+	if !ok {
+		return n.Tok, nil, nil, errf(n.Tok, "'%s' is not defined", n.Tok.GetLit())
+	}
+
+	args, err := executeAll(n.Args, env)
+	if err != nil {
+		return n.Tok, nil, nil, err
+	}
+
+	return n.Tok, fn, args, nil
+}
+
+func (n Call) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	tok, fn, args, err := n.prepare(env)
+	if err != nil {
+		return nil, err
+	}
+	return call(tok, fn, args)
+}
+
+// SelfCall is `@name [args]`, which always calls the enclosing function
+type SelfCall struct {
+	Tok  tokens.Token
+	Args []Node
+}
+
+func (n SelfCall) Str() string {
+	if len(n.Args) == 0 {
+		return "(@" + n.Tok.GetLit() + ")"
+	}
+	return "(@" + n.Tok.GetLit() + " " + joinStr(n.Args) + ")"
+}
+
+func (n SelfCall) prepare(env *environment.Environment) (tokens.Token, valuetypes.ValueType, []valuetypes.ValueType, error) {
+	fn, err := self(n.Tok, env)
+	if err != nil {
+		return n.Tok, nil, nil, err
+	}
+
+	args, err := executeAll(n.Args, env)
+	if err != nil {
+		return n.Tok, nil, nil, err
+	}
+
+	return n.Tok, fn, args, nil
+}
+
+func (n SelfCall) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	tok, fn, args, err := n.prepare(env)
+	if err != nil {
+		return nil, err
+	}
+	return call(tok, fn, args)
+}
+
+// Index is `value [i]`, which indexes any list, string, vector or bytes value; Tok is the `[`
+type Index struct {
+	Tok   tokens.Token
+	Value Node
+	Index Node
+}
+
+func (n Index) Str() string {
+	return "(index " + n.Value.Str() + " " + n.Index.Str() + ")"
+}
+
+func (n Index) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	value, err := n.Value.Execute(env)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := n.Index.Execute(env)
+	if err != nil {
+		return nil, err
+	}
+
+	xs, ok := value.(valuetypes.Indexable)
+	if !ok {
+		return nil, kindErrf(n.Tok, valuetypes.TypeError, "type '%s' cannot be indexed", value.Type())
+	}
+	return index(n.Tok, xs, i)
+}
+
+// Unary is a prefix operator, either `-` or `not`
+type Unary struct {
+	Op      tokens.Token
+	Operand Node
+}
+
+func (n Unary) Str() string {
+	return "(" + n.Op.GetLit() + " " + n.Operand.Str() + ")"
+}
+
+func (n Unary) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	operand, err := n.Operand.Execute(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Op.IsKind(tokens.Not) {
+		return booltype.New(!Truthy(operand)), nil
+	}
+
+	num, ok := operand.(numbertype.NumberType)
+	if !ok {
+		return nil, kindErrf(n.Op, valuetypes.TypeError, "type '%s' does not support negation", operand.Type())
+	}
+
+	result, err := num.Mul(numbertype.NewInt(-1))
+	if err != nil {
+		return nil, errAt(n.Op, err)
+	}
+	return result, nil
+}
+
+type Binary struct {
+	Op          tokens.Token
+	Left, Right Node
+}
+
+func (n Binary) Str() string {
+	return "(" + n.Op.GetLit() + " " + n.Left.Str() + " " + n.Right.Str() + ")"
+}
+
+func (n Binary) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	left, err := n.Left.Execute(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// `and` and `or` short-circuit, so the right side is only executed when needed
+	switch n.Op.GetKind() {
+	case tokens.And:
+		if !Truthy(left) {
+			return booltype.New(false), nil
+		}
+	case tokens.Or:
+		if Truthy(left) {
+			return booltype.New(true), nil
+		}
+	}
+
+	right, err := n.Right.Execute(env)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := binaryOp(n.Op, left, right)
+	if err != nil {
+		return nil, errAt(n.Op, err)
+	}
+	return result, nil
+}
+
+// Conditional is `Then if Cond else Else`; Tok is the `if`
+type Conditional struct {
+	Tok              tokens.Token
+	Then, Cond, Else Node
+}
+
+func (n Conditional) Str() string {
+	return "(if " + n.Cond.Str() + " " + n.Then.Str() + " " + n.Else.Str() + ")"
+}
+
+func (n Conditional) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	cond, err := n.Cond.Execute(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if Truthy(cond) {
+		return n.Then.Execute(env)
+	}
+	return n.Else.Execute(env)
+}
+
+// Pipe is `Value $ Target`, where Target is a Call, SelfCall or Ident that receives Value as its first argument
+type Pipe struct {
+	Tok           tokens.Token
+	Value, Target Node
+}
+
+func (n Pipe) Str() string {
+	return "($ " + n.Value.Str() + " " + n.Target.Str() + ")"
+}
+
+func (n Pipe) prepare(env *environment.Environment) (tokens.Token, valuetypes.ValueType, []valuetypes.ValueType, error) {
+	value, err := n.Value.Execute(env)
+	if err != nil {
+		return n.Tok, nil, nil, err
+	}
+
+	switch target := n.Target.(type) {
+	case Call:
+		tok, fn, args, err := target.prepare(env)
+		return tok, fn, append([]valuetypes.ValueType{value}, args...), err
+	case SelfCall:
+		tok, fn, args, err := target.prepare(env)
+		return tok, fn, append([]valuetypes.ValueType{value}, args...), err
+	}
+
+	fn, err := n.Target.Execute(env)
+	if err != nil {
+		return n.Tok, nil, nil, err
+	}
+	return n.Tok, fn, []valuetypes.ValueType{value}, nil
+}
+
+func (n Pipe) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	tok, fn, args, err := n.prepare(env)
+	if err != nil {
+		return nil, err
+	}
+	return call(tok, fn, args)
+}
+
+// FunDecl is `fun name = body` or `fun name [params] = body`;
+// Arity is the number of parameters, or the highest `#N` used when there is no parameter list,
+// and Doc holds the `///` comments written directly above the function
+type FunDecl struct {
+	Tok    tokens.Token
+	Doc    []tokens.Token
+	Name   string
+	Params []tokens.Token
+	// Patterns holds the patterns of destructured parameters, such as `(a, b)`, and nil for the rest; it's nil if none are destructured
+	Patterns []Pattern
+	Arity    int
+	Body     Node
+}
+
+func (n FunDecl) Str() string {
+	if n.Params == nil {
+		return "(fun " + n.Name + " " + n.Body.Str() + ")"
+	}
+
+	params := []string{}
+	for i, p := range n.Params {
+		if n.Patterns != nil && n.Patterns[i] != nil {
+			params = append(params, n.Patterns[i].Str())
+		} else {
+			params = append(params, p.GetLit())
+		}
+	}
+	return "(fun " + n.Name + " [" + strings.Join(params, " ") + "] " + n.Body.Str() + ")"
+}
+
+func (n FunDecl) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	// the function captures the scope it was defined in, so its body sees that scope's bindings
+	var fn funtype.FunType
+	fn = funtype.NewStepping(n.Name, n.Arity, func(args []valuetypes.ValueType) (valuetypes.ValueType, *funtype.TailCall, error) {
+		frame := environment.NewFrame(env, fn, args)
+		for i, p := range n.Params {
+			if n.Patterns == nil || n.Patterns[i] == nil {
+				frame.Define(p.GetLit(), args[i])
+			} else if ok, err := n.Patterns[i].Match(args[i], frame); err != nil {
+				return nil, nil, err
+			} else if !ok {
+				return nil, nil, errf(p, "argument %d of '%s' doesn't match the pattern %s, as it's %s", i+1, n.Name, n.Patterns[i].Str(), args[i].Fmt())
+			}
+		}
+		return executeTail(n.Body, frame)
+	})
+
+	if err := env.Define(n.Name, fn); err != nil {
+		return nil, errAt(n.Tok, err)
+	}
+	return fn, nil
+}
+
+type MyDecl struct {
+	Tok     tokens.Token
+	Name    string
+	Pattern Pattern // nil unless the value is destructured, as in `my (a, b) = ...`, in which case Name is empty
+	Value   Node
+}
+
+func (n MyDecl) Str() string {
+	if n.Pattern != nil {
+		return "(my " + n.Pattern.Str() + " " + n.Value.Str() + ")"
+	}
+	return "(my " + n.Name + " " + n.Value.Str() + ")"
+}
+
+func (n MyDecl) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	value, err := n.Value.Execute(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Pattern != nil {
+		if ok, err := n.Pattern.Match(value, env); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, errf(n.Tok, "the value %s doesn't match the pattern %s", value.Fmt(), n.Pattern.Str())
+		}
+		return value, nil
+	}
+
+	if err := env.Define(n.Name, value); err != nil {
+		return nil, errAt(n.Tok, err)
+	}
+	return value, nil
+}
+
+// TypeDecl is `type name = {fields}`, which declares a record type and the constructor named after it
+type TypeDecl struct {
+	Tok    tokens.Token
+	Name   string
+	Fields []tokens.Token
+}
+
+func (n TypeDecl) Str() string {
+	fields := []string{}
+	for _, f := range n.Fields {
+		fields = append(fields, f.GetLit())
+	}
+	return "(type " + n.Name + " (" + strings.Join(fields, " ") + "))"
+}
+
+func (n TypeDecl) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	fields := []string{}
+	for _, f := range n.Fields {
+		fields = append(fields, f.GetLit())
+	}
+	shape := recordtype.NewShape(n.Name, fields)
+
+	constructor := funtype.New(n.Name, len(fields), func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+		return recordtype.New(shape, args), nil
+	})
+
+	if err := env.Define(n.Name, constructor); err != nil {
+		return nil, errAt(n.Tok, err)
+	}
+	env.DefineShape(n.Name, shape)
+	return constructor, nil
+}
+
+type Variant struct {
+	Tok    tokens.Token
+	Fields []tokens.Token
+}
+
+// UnionDecl is `type name = variant | variant [fields] | ...`, which declares a tagged union
+type UnionDecl struct {
+	Tok      tokens.Token
+	Name     string
+	Variants []Variant
+}
+
+func (n UnionDecl) Str() string {
+	variants := []string{}
+	for _, v := range n.Variants {
+		fields := []string{}
+		for _, f := range v.Fields {
+			fields = append(fields, f.GetLit())
+		}
+		variants = append(variants, "("+v.Tok.GetLit()+" "+strings.Join(fields, " ")+")")
+	}
+	return "(type " + n.Name + " " + strings.Join(variants, " ") + ")"
+}
+
+// Execute defines a constructor for each variant with fields, and the value of each variant without them
+func (n UnionDecl) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	var last valuetypes.ValueType
+	for _, v := range n.Variants {
+		fields := []string{}
+		for _, f := range v.Fields {
+			fields = append(fields, f.GetLit())
+		}
+		shape := recordtype.NewVariant(n.Name, v.Tok.GetLit(), fields)
+
+		if len(fields) == 0 {
+			last = recordtype.New(shape, []valuetypes.ValueType{})
+		} else {
+			last = funtype.New(v.Tok.GetLit(), len(fields), func(args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+				return recordtype.New(shape, args), nil
+			})
+		}
+
+		if err := env.Define(v.Tok.GetLit(), last); err != nil {
+			return nil, errAt(v.Tok, err)
+		}
+		env.DefineShape(v.Tok.GetLit(), shape)
+	}
+	return last, nil
+}
+
+// Field is `record.name`; Tok is the field name
+type Field struct {
+	Tok    tokens.Token
+	Record Node
+	Name   string
+}
+
+func (n Field) Str() string {
+	return "(. " + n.Record.Str() + " " + n.Name + ")"
+}
+
+func (n Field) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	record, err := n.Record.Execute(env)
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := record.(recordtype.RecordType)
+	if !ok {
+		return nil, kindErrf(n.Tok, valuetypes.TypeError, "type '%s' has no fields", record.Type())
+	}
+
+	v, ok := r.Field(n.Name)
+	if !ok {
+		return nil, kindErrf(n.Tok, valuetypes.TypeError, "type '%s' has no field '%s'", r.Type(), n.Name)
+	}
+	return v, nil
+}
+
+type MatchArm struct {
+	Pattern Pattern
+	Guard   Node // nil if the arm has no `if guard`
+	Body    Node
+}
+
+// Match is `match value { pattern -> body, pattern if guard -> body, ... }`; Tok is the `match`
+type Match struct {
+	Tok   tokens.Token
+	Value Node
+	Arms  []MatchArm
+}
+
+func (n Match) Str() string {
+	arms := []string{}
+	for _, a := range n.Arms {
+		if a.Guard != nil {
+			arms = append(arms, "("+a.Pattern.Str()+" if "+a.Guard.Str()+" "+a.Body.Str()+")")
+		} else {
+			arms = append(arms, "("+a.Pattern.Str()+" "+a.Body.Str()+")")
+		}
+	}
+	return "(match " + n.Value.Str() + " " + strings.Join(arms, " ") + ")"
+}
+
+// arm finds the first arm that matches the value, and returns it with the scope holding the names its pattern binds
+func (n Match) arm(env *environment.Environment) (MatchArm, *environment.Environment, error) {
+	value, err := n.Value.Execute(env)
+	if err != nil {
+		return MatchArm{}, nil, err
+	}
+
+	for _, a := range n.Arms {
+		scope := environment.New(env)
+		if ok, err := a.Pattern.Match(value, scope); err != nil {
+			return MatchArm{}, nil, err
+		} else if !ok {
+			continue
+		}
+
+		if a.Guard != nil {
+			guard, err := a.Guard.Execute(scope)
+			if err != nil {
+				return MatchArm{}, nil, err
+			} else if !Truthy(guard) {
+				continue
+			}
+		}
+		return a, scope, nil
+	}
+
+	return MatchArm{}, nil, errf(n.Tok, "no pattern matched the value %s", value.Fmt())
+}
+
+func (n Match) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	a, scope, err := n.arm(env)
+	if err != nil {
+		return nil, err
+	}
+	return a.Body.Execute(scope)
+}
+
+/*
+Try is `try body catch pattern -> handler`; Tok is the `try`.
+An error in the body is caught as an `error` value, and if that matches the pattern, the handler's value is used instead;
+otherwise the error carries on as if it hadn't been caught.
+*/
+type Try struct {
+	Tok     tokens.Token
+	Body    Node
+	Pattern Pattern
+	Handler Node
+}
+
+func (n Try) Str() string {
+	return "(try " + n.Body.Str() + " (" + n.Pattern.Str() + " " + n.Handler.Str() + "))"
+}
+
+// catch executes the body, and if it fails with an error the pattern matches, returns the scope the handler should be executed in
+func (n Try) catch(env *environment.Environment) (valuetypes.ValueType, *environment.Environment, error) {
+	value, err := n.Body.Execute(env)
+	if err == nil {
+		return value, nil, nil
+	}
+
+	d := diagnostics.From(err)
+	kind := d.Kind
+	if kind == "" {
+		kind = valuetypes.Kind(err)
+	}
+	caught := recordtype.NewError(d.Message, kind, max(d.StartLn, 0), max(d.StartCol, 0))
+
+	scope := environment.New(env)
+	if ok, matchErr := n.Pattern.Match(caught, scope); matchErr != nil {
+		return nil, nil, matchErr
+	} else if !ok {
+		return nil, nil, err
+	}
+	return nil, scope, nil
+}
+
+func (n Try) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	value, scope, err := n.catch(env)
+	if err != nil || scope == nil {
+		return value, err
+	}
+	return n.Handler.Execute(scope)
+}
+
+// Block is a parenthesized sequence of statements with its own scope, such as `(my x = 1; x + 1)`;
+// its value is that of the last statement
+type Block struct {
+	Tok  tokens.Token
+	Body []Node
+}
+
+func (n Block) Str() string {
+	return "(do " + joinStr(n.Body) + ")"
+}
+
+func (n Block) Execute(env *environment.Environment) (valuetypes.ValueType, error) {
+	scope := environment.New(env)
+
+	var result valuetypes.ValueType
+	for _, stmt := range n.Body {
+		v, err := stmt.Execute(scope)
+		if err != nil {
+			return nil, err
+		}
+		result = v
+	}
+	return result, nil
+}
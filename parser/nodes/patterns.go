@@ -0,0 +1,197 @@
+package nodes
+
+import (
+	"strings"
+
+	"github.com/voidwyrm-2/opal/interpreter/environment"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/listtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/recordtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/tupletype"
+	"github.com/voidwyrm-2/opal/lexer/tokens"
+)
+
+// Pattern is the left side of an arm of a `match`
+type Pattern interface {
+	// Match reports whether val fits the pattern, defining the names the pattern binds in env as it goes
+	Match(val valuetypes.ValueType, env *environment.Environment) (bool, error)
+	Str() string
+}
+
+// WildcardPattern is `_`, which matches anything and binds nothing
+type WildcardPattern struct {
+	Tok tokens.Token
+}
+
+func (p WildcardPattern) Str() string {
+	return "_"
+}
+
+func (p WildcardPattern) Match(val valuetypes.ValueType, env *environment.Environment) (bool, error) {
+	return true, nil
+}
+
+/*
+NamePattern is a plain name.
+If the name is a variant without fields, such as `leaf`, it only matches that variant;
+otherwise it matches anything, and binds the value to the name.
+*/
+type NamePattern struct {
+	Tok tokens.Token
+}
+
+func (p NamePattern) Str() string {
+	return p.Tok.GetLit()
+}
+
+// nullaryVariant returns the variant without fields that name refers to, if it refers to one
+func nullaryVariant(name string, env *environment.Environment) (recordtype.Shape, bool) {
+	shape, ok := env.Shape(name)
+	if !ok {
+		return recordtype.Shape{}, false
+	} else if _, isVariant := shape.Union(); !isVariant || len(shape.Fields()) != 0 {
+		return recordtype.Shape{}, false
+	}
+	return shape, true
+}
+
+func (p NamePattern) Match(val valuetypes.ValueType, env *environment.Environment) (bool, error) {
+	name := p.Tok.GetLit()
+	if shape, ok := nullaryVariant(name, env); ok {
+		r, ok := val.(recordtype.RecordType)
+		return ok && r.Shape().SameAs(shape), nil
+	}
+
+	if err := env.Define(name, val); err != nil {
+		return false, errAt(p.Tok, err)
+	}
+	return true, nil
+}
+
+// LiteralPattern is a number, string, char or boolean, which matches values equal to it
+type LiteralPattern struct {
+	Tok   tokens.Token
+	Value Node
+}
+
+func (p LiteralPattern) Str() string {
+	return p.Value.Str()
+}
+
+func (p LiteralPattern) Match(val valuetypes.ValueType, env *environment.Environment) (bool, error) {
+	lit, err := p.Value.Execute(env)
+	if err != nil {
+		return false, err
+	}
+
+	eq, err := lit.Equals(val)
+	if err != nil {
+		return false, errAt(p.Tok, err)
+	}
+	return eq.Lit() == true, nil
+}
+
+// ConstructorPattern is `name [patterns]`, which matches records and variants made by the constructor name refers to
+type ConstructorPattern struct {
+	Tok  tokens.Token
+	Args []Pattern
+}
+
+func (p ConstructorPattern) Str() string {
+	args := []string{}
+	for _, a := range p.Args {
+		args = append(args, a.Str())
+	}
+	return "(" + p.Tok.GetLit() + " " + strings.Join(args, " ") + ")"
+}
+
+func (p ConstructorPattern) Match(val valuetypes.ValueType, env *environment.Environment) (bool, error) {
+	shape, ok := env.Shape(p.Tok.GetLit())
+	if !ok {
+		return false, errf(p.Tok, "'%s' is not a record type or a variant", p.Tok.GetLit())
+	} else if len(shape.Fields()) != len(p.Args) {
+		return false, errf(p.Tok, "'%s' has %d field(s), but the pattern has %d", p.Tok.GetLit(), len(shape.Fields()), len(p.Args))
+	}
+
+	// the value has to be made by the constructor the name refers to here, not just one with the same name
+	r, ok := val.(recordtype.RecordType)
+	if !ok || !r.Shape().SameAs(shape) {
+		return false, nil
+	}
+
+	for i, arg := range p.Args {
+		if ok, err := arg.Match(r.Values()[i], env); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// TuplePattern is `(patterns)`, which matches tuples with that many elements
+type TuplePattern struct {
+	Tok   tokens.Token
+	Elems []Pattern
+}
+
+func (p TuplePattern) Str() string {
+	elems := []string{}
+	for _, e := range p.Elems {
+		elems = append(elems, e.Str())
+	}
+	return "(" + strings.Join(elems, ", ") + ")"
+}
+
+func (p TuplePattern) Match(val valuetypes.ValueType, env *environment.Environment) (bool, error) {
+	t, ok := val.(tupletype.TupleType)
+	if !ok || t.Len() != len(p.Elems) {
+		return false, nil
+	}
+
+	for i, elem := range t.Values() {
+		if ok, err := p.Elems[i].Match(elem, env); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+/*
+ListPattern is `[patterns]`, which matches lists with exactly that many elements,
+or `[patterns | rest]`, which matches lists with at least that many, matching rest against the ones after them.
+*/
+type ListPattern struct {
+	Tok   tokens.Token
+	Elems []Pattern
+	Rest  Pattern // nil if there's no `| rest`
+}
+
+func (p ListPattern) Str() string {
+	elems := []string{}
+	for _, e := range p.Elems {
+		elems = append(elems, e.Str())
+	}
+	if p.Rest != nil {
+		elems = append(elems, "|", p.Rest.Str())
+	}
+	return "[" + strings.Join(elems, " ") + "]"
+}
+
+func (p ListPattern) Match(val valuetypes.ValueType, env *environment.Environment) (bool, error) {
+	l, ok := val.(listtype.ListType)
+	if !ok || l.Len() < len(p.Elems) || p.Rest == nil && l.Len() != len(p.Elems) {
+		return false, nil
+	}
+
+	for _, elem := range p.Elems {
+		head, _ := l.Head()
+		if ok, err := elem.Match(head, env); !ok || err != nil {
+			return false, err
+		}
+		l, _ = l.Tail()
+	}
+
+	if p.Rest != nil {
+		return p.Rest.Match(l, env)
+	}
+	return true, nil
+}
@@ -0,0 +1,227 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/voidwyrm-2/opal/diagnostics"
+	"github.com/voidwyrm-2/opal/interpreter/environment"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/booltype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/bytestype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/funtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/listtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/numbertype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/settype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/stringtype"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/vectortype"
+	"github.com/voidwyrm-2/opal/lexer/tokens"
+)
+
+func errf(tok tokens.Token, format string, a ...any) error {
+	return kindErrf(tok, valuetypes.GeneralError, format, a...)
+}
+
+// kindErrf is errf for errors of a kind other than the general one, which `catch` can tell apart
+func kindErrf(tok tokens.Token, kind string, format string, a ...any) error {
+	return tok.Err(diagnostics.Runtime, format, a...).WithKind(kind)
+}
+
+// errAt gives an error the position of tok, unless it's already a diagnostic with a position of its own
+func errAt(tok tokens.Token, err error) error {
+	var d diagnostics.Diagnostic
+	if errors.As(err, &d) && d.HasPos() {
+		return err
+	}
+	return kindErrf(tok, valuetypes.Kind(err), "%s", err.Error())
+}
+
+/*
+Truthy reports whether a value counts as true for `if`, `and`, `or`, `not` and the predicates given to builtins such as `filter`.
+False, the number 0, and the empty string, list, vector, set and bytes are false; everything else is true.
+*/
+func Truthy(val valuetypes.ValueType) bool {
+	switch v := val.(type) {
+	case booltype.BoolType:
+		return v.Lit().(bool)
+	case numbertype.NumberType:
+		return !v.IsZero()
+	case stringtype.StringType:
+		return v.Len() != 0
+	case listtype.ListType:
+		return v.Len() != 0
+	case vectortype.VectorType:
+		return v.Len() != 0
+	case settype.SetType:
+		return v.Len() != 0
+	case bytestype.BytesType:
+		return v.Len() != 0
+	}
+	return true
+}
+
+func call(tok tokens.Token, fn valuetypes.ValueType, args []valuetypes.ValueType) (valuetypes.ValueType, error) {
+	f, ok := fn.(funtype.FunType)
+	if xs, indexable := fn.(valuetypes.Indexable); indexable && len(args) == 1 {
+		return index(tok, xs, args[0])
+	} else if !ok {
+		return nil, kindErrf(tok, valuetypes.TypeError, "'%s' is of type '%s', which cannot be called", tok.GetLit(), fn.Type())
+	}
+
+	result, err := f.Call(args)
+	if err != nil {
+		return nil, errAt(tok, err)
+	}
+	return result, nil
+}
+
+// index is what calling a list, string or vector does; `xs [i]` is the element of xs at index i
+func index(tok tokens.Token, xs valuetypes.Indexable, i valuetypes.ValueType) (valuetypes.ValueType, error) {
+	n, ok := i.(numbertype.NumberType)
+	if !ok {
+		return nil, kindErrf(tok, valuetypes.TypeError, "type '%s' cannot be indexed by type '%s'", xs.Type(), i.Type())
+	}
+
+	pos, ok := n.Int()
+	if !ok {
+		return nil, kindErrf(tok, valuetypes.TypeError, "type '%s' can only be indexed by integers, not %s", xs.Type(), n.Fmt())
+	}
+
+	elem, err := xs.Index(int(pos))
+	if err != nil {
+		return nil, errAt(tok, err)
+	}
+	return elem, nil
+}
+
+func self(tok tokens.Token, env *environment.Environment) (valuetypes.ValueType, error) {
+	fn, ok := env.Self()
+	if !ok {
+		return nil, errf(tok, "'@%s' used outside of a function", tok.GetLit())
+	}
+	return fn, nil
+}
+
+// tailCall is call for calls in tail position, which hands calls to Opal functions back to be made by FunType.Call
+func tailCall(tok tokens.Token, fn valuetypes.ValueType, args []valuetypes.ValueType) (valuetypes.ValueType, *funtype.TailCall, error) {
+	f, ok := fn.(funtype.FunType)
+	if !ok || !f.Steps() {
+		v, err := call(tok, fn, args)
+		return v, nil, err
+	}
+
+	// the arity is checked here, while the position of the call is still known
+	if err := f.CheckArity(args); err != nil {
+		return nil, nil, errAt(tok, err)
+	}
+	return nil, &funtype.TailCall{Fn: f, Args: args}, nil
+}
+
+/*
+executeTail executes a node in tail position, which a function body is.
+Conditionals, matches, blocks and tries pass the tail position on to their branches, arms, last statement and handler,
+and calls in it are returned as TailCalls rather than made, so they don't use any Go stack.
+*/
+func executeTail(n Node, env *environment.Environment) (valuetypes.ValueType, *funtype.TailCall, error) {
+	switch n := n.(type) {
+	case Call:
+		tok, fn, args, err := n.prepare(env)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tailCall(tok, fn, args)
+	case SelfCall:
+		tok, fn, args, err := n.prepare(env)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tailCall(tok, fn, args)
+	case Pipe:
+		tok, fn, args, err := n.prepare(env)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tailCall(tok, fn, args)
+	case Conditional:
+		cond, err := n.Cond.Execute(env)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if Truthy(cond) {
+			return executeTail(n.Then, env)
+		}
+		return executeTail(n.Else, env)
+	case Match:
+		a, scope, err := n.arm(env)
+		if err != nil {
+			return nil, nil, err
+		}
+		return executeTail(a.Body, scope)
+	case Try:
+		// the body can't be in tail position, or its errors would happen after it had been left
+		value, scope, err := n.catch(env)
+		if err != nil || scope == nil {
+			return value, nil, err
+		}
+		return executeTail(n.Handler, scope)
+	case Block:
+		scope := environment.New(env)
+		for _, stmt := range n.Body[:len(n.Body)-1] {
+			if _, err := stmt.Execute(scope); err != nil {
+				return nil, nil, err
+			}
+		}
+		return executeTail(n.Body[len(n.Body)-1], scope)
+	}
+
+	v, err := n.Execute(env)
+	return v, nil, err
+}
+
+func binaryOp(op tokens.Token, left, right valuetypes.ValueType) (valuetypes.ValueType, error) {
+	switch op.GetKind() {
+	case tokens.Plus:
+		return left.Add(right)
+	case tokens.Concat:
+		return left.Concat(right)
+	case tokens.Hyphen:
+		return left.Sub(right)
+	case tokens.Asterisk:
+		return left.Mul(right)
+	case tokens.ForwardSlash:
+		return left.Div(right)
+	case tokens.Percent:
+		return left.Mod(right)
+	case tokens.BitAnd:
+		return left.BitAnd(right)
+	case tokens.BitOr:
+		return left.BitOr(right)
+	case tokens.BitXOR:
+		return left.BitXOR(right)
+	case tokens.Equals:
+		return left.Equals(right)
+	case tokens.NotEquals:
+		return left.NotEquals(right)
+	case tokens.GreaterThan:
+		return left.GreaterThan(right)
+	case tokens.LesserThan:
+		return left.LesserThan(right)
+	case tokens.GreaterThanOrEqualTo:
+		lt, err := left.LesserThan(right)
+		if err != nil {
+			return nil, err
+		}
+		return booltype.New(!Truthy(lt)), nil
+	case tokens.LesserThanOrEqualTo:
+		gt, err := left.GreaterThan(right)
+		if err != nil {
+			return nil, err
+		}
+		return booltype.New(!Truthy(gt)), nil
+	case tokens.And, tokens.Or:
+		// the left side has already been checked by the caller, so the right side decides
+		return booltype.New(Truthy(right)), nil
+	}
+	panic(fmt.Sprintf("invalid binary operator %s", op.GetKind().Str()))
+}
@@ -0,0 +1,858 @@
+package parser
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/diagnostics"
+	"github.com/voidwyrm-2/opal/lexer/tokens"
+	"github.com/voidwyrm-2/opal/parser/nodes"
+)
+
+// funContext describes the function currently being parsed, and is used to validate `@name` and `#N`
+type funContext struct {
+	name   string
+	params int // the number of named parameters, or -1 if there is no parameter list
+	maxArg int // the highest `#N` used directly in the body
+}
+
+type Parser struct {
+	toks []tokens.Token
+	idx  int
+	fun  *funContext
+}
+
+func New(toks []tokens.Token) Parser {
+	// doc comments only mean something directly before a function, so any others are dropped
+	filtered := []tokens.Token{}
+	for i, t := range toks {
+		if t.IsKind(tokens.DocComment) {
+			j := i
+			for j < len(toks) && toks[j].IsKind(tokens.DocComment) {
+				j++
+			}
+			if j == len(toks) || !toks[j].IsKind(tokens.Fun) {
+				continue
+			}
+		}
+		filtered = append(filtered, t)
+	}
+
+	return Parser{toks: filtered, idx: 0, fun: nil}
+}
+
+func (p Parser) atEnd() bool {
+	return p.idx >= len(p.toks)
+}
+
+func (p Parser) cur() tokens.Token {
+	if p.atEnd() {
+		return tokens.Empty()
+	}
+	return p.toks[p.idx]
+}
+
+func (p Parser) peek() tokens.Token {
+	if p.idx+1 < len(p.toks) {
+		return p.toks[p.idx+1]
+	}
+	return tokens.Empty()
+}
+
+func (p *Parser) advance() {
+	p.idx++
+}
+
+func (p Parser) eofErr() error {
+	if len(p.toks) == 0 {
+		return tokens.Empty().Err(diagnostics.UnexpectedEnd, "unexpected end of input")
+	}
+	return p.toks[len(p.toks)-1].Err(diagnostics.UnexpectedEnd, "unexpected end of input")
+}
+
+func (p Parser) unexpected() error {
+	if p.atEnd() {
+		return p.eofErr()
+	}
+	return p.cur().Err(diagnostics.UnexpectedToken, "unexpected token '%s'", p.cur().GetLit())
+}
+
+func (p *Parser) expect(kind tokens.TokenType, what string) (tokens.Token, error) {
+	if p.atEnd() {
+		return tokens.Token{}, p.eofErr()
+	} else if !p.cur().IsKind(kind) {
+		return tokens.Token{}, p.cur().Err(diagnostics.UnexpectedToken, "expected %s, but found '%s'", what, p.cur().GetLit())
+	}
+	tok := p.cur()
+	p.advance()
+	return tok, nil
+}
+
+func (p *Parser) Parse() ([]nodes.Node, error) {
+	program := []nodes.Node{}
+
+	for !p.atEnd() {
+		if p.cur().IsKind(tokens.Semicolon) {
+			p.advance()
+			continue
+		}
+
+		n, err := p.parseStatement()
+		if err != nil {
+			return []nodes.Node{}, err
+		}
+		program = append(program, n)
+
+		// the semicolon may be left out before another declaration or at the end of the file
+		if p.cur().IsKind(tokens.Semicolon) {
+			p.advance()
+		} else if !p.atEnd() && !p.cur().IsKind(tokens.Fun) && !p.cur().IsKind(tokens.DocComment) && !p.cur().IsKind(tokens.My) && !p.cur().IsKind(tokens.Type) {
+			return []nodes.Node{}, p.cur().Err(diagnostics.UnexpectedToken, "expected ';', but found '%s'", p.cur().GetLit())
+		}
+	}
+
+	return program, nil
+}
+
+func (p *Parser) parseStatement() (nodes.Node, error) {
+	switch p.cur().GetKind() {
+	case tokens.DocComment, tokens.Fun:
+		return p.parseFun()
+	case tokens.My:
+		return p.parseMy()
+	case tokens.Type:
+		return p.parseType()
+	default:
+		return p.parseExpr()
+	}
+}
+
+func (p *Parser) parseFun() (nodes.Node, error) {
+	doc := []tokens.Token{}
+	for p.cur().IsKind(tokens.DocComment) {
+		doc = append(doc, p.cur())
+		p.advance()
+	}
+
+	tok := p.cur()
+	p.advance()
+
+	name, err := p.expect(tokens.Ident, "function name")
+	if err != nil {
+		return nil, err
+	}
+
+	var params []tokens.Token
+	var patterns []nodes.Pattern
+	if p.cur().IsKind(tokens.OpenBracket) {
+		p.advance()
+		if params, patterns, err = p.parseParams(name.GetLit()); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.expect(tokens.Assign, "'='"); err != nil {
+		return nil, err
+	}
+
+	ctx := &funContext{name: name.GetLit(), params: -1, maxArg: 0}
+	if params != nil {
+		ctx.params = len(params)
+	}
+
+	outer := p.fun
+	p.fun = ctx
+	body, err := p.parseExpr()
+	p.fun = outer
+	if err != nil {
+		return nil, err
+	}
+
+	arity := ctx.maxArg
+	if params != nil {
+		arity = len(params)
+	}
+
+	return nodes.FunDecl{Tok: tok, Doc: doc, Name: name.GetLit(), Params: params, Patterns: patterns, Arity: arity, Body: body}, nil
+}
+
+/*
+parseParams parses a parameter list such as `[xs, n]` after its opening bracket.
+A parameter may also be a tuple pattern such as `(a, b)`, which destructures its argument;
+the patterns are returned alongside the parameters, with nil for plain names, or not at all if there are none.
+*/
+func (p *Parser) parseParams(fun string) ([]tokens.Token, []nodes.Pattern, error) {
+	params := []tokens.Token{}
+	patterns := []nodes.Pattern{}
+	destructures := false
+
+	if p.cur().IsKind(tokens.CloseBracket) {
+		p.advance()
+		return params, nil, nil
+	}
+
+	for {
+		if p.cur().IsKind(tokens.OpenParen) {
+			tok := p.cur()
+			pattern, err := p.parseTuplePattern()
+			if err != nil {
+				return []tokens.Token{}, nil, err
+			}
+			params = append(params, tok)
+			patterns = append(patterns, pattern)
+			destructures = true
+		} else {
+			param, err := p.expect(tokens.Ident, "parameter name")
+			if err != nil {
+				return []tokens.Token{}, nil, err
+			}
+
+			for i, other := range params {
+				if patterns[i] == nil && other.GetLit() == param.GetLit() {
+					return []tokens.Token{}, nil, param.Err(diagnostics.DuplicateParameter, "duplicate parameter '%s' in function '%s'", param.GetLit(), fun)
+				}
+			}
+			params = append(params, param)
+			patterns = append(patterns, nil)
+		}
+
+		if p.cur().IsKind(tokens.Comma) {
+			p.advance()
+		} else if _, err := p.expect(tokens.CloseBracket, "',' or ']'"); err != nil {
+			return []tokens.Token{}, nil, err
+		} else if !destructures {
+			return params, nil, nil
+		} else {
+			return params, patterns, nil
+		}
+	}
+}
+
+func (p *Parser) parseMy() (nodes.Node, error) {
+	tok := p.cur()
+	p.advance()
+
+	var name tokens.Token
+	var pattern nodes.Pattern
+	var err error
+	if p.cur().IsKind(tokens.OpenParen) {
+		if pattern, err = p.parseTuplePattern(); err != nil {
+			return nil, err
+		}
+	} else if name, err = p.expect(tokens.Ident, "variable name"); err != nil {
+		return nil, err
+	}
+
+	if _, err := p.expect(tokens.Assign, "'='"); err != nil {
+		return nil, err
+	}
+
+	value, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	if pattern != nil {
+		return nodes.MyDecl{Tok: tok, Name: "", Pattern: pattern, Value: value}, nil
+	}
+	return nodes.MyDecl{Tok: tok, Name: name.GetLit(), Pattern: nil, Value: value}, nil
+}
+
+// builtinTypes can't be the names of record types, as values are told apart by the names of their types
+var builtinTypes = []string{"number", "string", "char", "boolean", "list", "vector", "sequence", "map", "set", "bytes", "tuple", "unit", "option", "error", "fun", "any"}
+
+// parseType parses a record type declaration, `type name = {field, ...}`, or a tagged union, `type name = variant | ...`
+func (p *Parser) parseType() (nodes.Node, error) {
+	tok := p.cur()
+	p.advance()
+
+	name, err := p.expect(tokens.Ident, "type name")
+	if err != nil {
+		return nil, err
+	} else if slices.Contains(builtinTypes, name.GetLit()) {
+		return nil, name.Err(diagnostics.InvalidTypeName, "'%s' is already the name of a builtin type", name.GetLit())
+	} else if strings.Contains(name.GetLit(), ".") {
+		return nil, name.Err(diagnostics.InvalidTypeName, "type names cannot contain '.'")
+	}
+
+	if _, err := p.expect(tokens.Assign, "'='"); err != nil {
+		return nil, err
+	}
+
+	if p.cur().IsKind(tokens.OpenBrace) {
+		p.advance()
+		fields, err := p.parseFieldNames(name.GetLit(), tokens.CloseBrace, "',' or '}'")
+		if err != nil {
+			return nil, err
+		}
+		return nodes.TypeDecl{Tok: tok, Name: name.GetLit(), Fields: fields}, nil
+	}
+
+	variants := []nodes.Variant{}
+	for {
+		variant, err := p.expect(tokens.Ident, "variant name")
+		if err != nil {
+			return nil, err
+		} else if strings.Contains(variant.GetLit(), ".") {
+			return nil, variant.Err(diagnostics.InvalidTypeName, "variant names cannot contain '.'")
+		}
+
+		fields := []tokens.Token{}
+		if p.cur().IsKind(tokens.OpenBracket) {
+			p.advance()
+			if fields, err = p.parseFieldNames(variant.GetLit(), tokens.CloseBracket, "',' or ']'"); err != nil {
+				return nil, err
+			}
+		}
+		variants = append(variants, nodes.Variant{Tok: variant, Fields: fields})
+
+		if !p.cur().IsKind(tokens.BitOr) {
+			return nodes.UnionDecl{Tok: tok, Name: name.GetLit(), Variants: variants}, nil
+		}
+		p.advance()
+	}
+}
+
+// parseFieldNames parses the comma-separated field names of a record or variant, up to and including the closing token
+func (p *Parser) parseFieldNames(of string, closing tokens.TokenType, what string) ([]tokens.Token, error) {
+	fields := []tokens.Token{}
+	seen := map[string]bool{}
+	for !p.cur().IsKind(closing) {
+		field, err := p.expect(tokens.Ident, "field name")
+		if err != nil {
+			return nil, err
+		} else if seen[field.GetLit()] {
+			return nil, field.Err(diagnostics.DuplicateParameter, "duplicate field '%s' in '%s'", field.GetLit(), of)
+		} else if strings.Contains(field.GetLit(), ".") {
+			return nil, field.Err(diagnostics.UnexpectedToken, "field names cannot contain '.'")
+		}
+		seen[field.GetLit()] = true
+		fields = append(fields, field)
+
+		if !p.cur().IsKind(tokens.Comma) {
+			break
+		}
+		p.advance()
+	}
+
+	if _, err := p.expect(closing, what); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// parseMatch parses the value and arms of a `match`, after the `match`
+func (p *Parser) parseMatch(tok tokens.Token) (nodes.Node, error) {
+	value, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	} else if _, err := p.expect(tokens.OpenBrace, "'{'"); err != nil {
+		return nil, err
+	}
+
+	arms := []nodes.MatchArm{}
+	for !p.cur().IsKind(tokens.CloseBrace) || len(arms) == 0 {
+		pattern, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+
+		// the guard binds tighter than a conditional, so its `if` can't be mistaken for one
+		var guard nodes.Node
+		if p.cur().IsKind(tokens.If) {
+			p.advance()
+			if guard, err = p.parsePrec(precOr); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := p.expect(tokens.Arrow, "'->'"); err != nil {
+			return nil, err
+		}
+		body, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		arms = append(arms, nodes.MatchArm{Pattern: pattern, Guard: guard, Body: body})
+
+		if !p.cur().IsKind(tokens.Comma) {
+			break
+		}
+		p.advance()
+	}
+
+	if _, err := p.expect(tokens.CloseBrace, "',' or '}'"); err != nil {
+		return nil, err
+	}
+	return nodes.Match{Tok: tok, Value: value, Arms: arms}, nil
+}
+
+// parseTry parses `try body catch pattern -> handler`, after the `try`
+func (p *Parser) parseTry(tok tokens.Token) (nodes.Node, error) {
+	body, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	} else if _, err := p.expect(tokens.Catch, "'catch'"); err != nil {
+		return nil, err
+	}
+
+	pattern, err := p.parsePattern()
+	if err != nil {
+		return nil, err
+	} else if _, err := p.expect(tokens.Arrow, "'->'"); err != nil {
+		return nil, err
+	}
+
+	handler, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return nodes.Try{Tok: tok, Body: body, Pattern: pattern, Handler: handler}, nil
+}
+
+func (p *Parser) parsePattern() (nodes.Pattern, error) {
+	tok := p.cur()
+	if p.atEnd() {
+		return nil, p.eofErr()
+	}
+
+	switch tok.GetKind() {
+	case tokens.Ident:
+		p.advance()
+		if strings.Contains(tok.GetLit(), ".") {
+			return nil, tok.Err(diagnostics.UnexpectedToken, "patterns cannot contain '.'")
+		} else if tok.GetLit() == "_" {
+			return nodes.WildcardPattern{Tok: tok}, nil
+		} else if !p.cur().IsKind(tokens.OpenBracket) {
+			return nodes.NamePattern{Tok: tok}, nil
+		}
+
+		p.advance()
+		args, _, err := p.parsePatternList(tokens.CloseBracket, false)
+		if err != nil {
+			return nil, err
+		}
+		return nodes.ConstructorPattern{Tok: tok, Args: args}, nil
+	case tokens.OpenBracket:
+		p.advance()
+		elems, rest, err := p.parsePatternList(tokens.CloseBracket, true)
+		if err != nil {
+			return nil, err
+		}
+		return nodes.ListPattern{Tok: tok, Elems: elems, Rest: rest}, nil
+	case tokens.OpenParen:
+		return p.parseTuplePattern()
+	case tokens.Number, tokens.String, tokens.Bytes, tokens.Char, tokens.Bool:
+		value, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return nodes.LiteralPattern{Tok: tok, Value: value}, nil
+	case tokens.Hyphen:
+		if !p.peek().IsKind(tokens.Number) {
+			break
+		}
+		p.advance()
+		value, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return nodes.LiteralPattern{Tok: tok, Value: nodes.Unary{Op: tok, Operand: value}}, nil
+	}
+
+	return nil, p.cur().Err(diagnostics.UnexpectedToken, "expected a pattern, but found '%s'", tok.GetLit())
+}
+
+// parseTuplePattern parses `(patterns)`, as used in matches, and to destructure `my` bindings and parameters
+func (p *Parser) parseTuplePattern() (nodes.Pattern, error) {
+	tok := p.cur()
+	p.advance()
+
+	elems, _, err := p.parsePatternList(tokens.CloseParen, false)
+	if err != nil {
+		return nil, err
+	}
+	return nodes.TuplePattern{Tok: tok, Elems: elems}, nil
+}
+
+// parsePatternList parses patterns up to a closing bracket or parenthesis; list patterns may end with `| rest`
+func (p *Parser) parsePatternList(closing tokens.TokenType, list bool) ([]nodes.Pattern, nodes.Pattern, error) {
+	pats := []nodes.Pattern{}
+	var rest nodes.Pattern
+
+	for !p.cur().IsKind(closing) {
+		pat, err := p.parsePattern()
+		if err != nil {
+			return nil, nil, err
+		}
+		pats = append(pats, pat)
+
+		if list && len(pats) != 0 && p.cur().IsKind(tokens.BitOr) {
+			p.advance()
+			if rest, err = p.parsePattern(); err != nil {
+				return nil, nil, err
+			}
+			break
+		} else if !p.cur().IsKind(tokens.Comma) {
+			break
+		}
+		p.advance()
+	}
+
+	what := "',' or ']'"
+	if list {
+		what = "',', '|' or ']'"
+	} else if closing == tokens.CloseParen {
+		what = "',' or ')'"
+	}
+	if _, err := p.expect(closing, what); err != nil {
+		return nil, nil, err
+	}
+	return pats, rest, nil
+}
+
+// parseFields turns a dotted identifier such as `p.pos.x` into field accesses on the variable before the first dot
+func (p *Parser) parseFields(tok tokens.Token) (nodes.Node, error) {
+	parts := strings.Split(tok.GetLit(), ".")
+	col := tok.GetCol()
+
+	var n nodes.Node
+	for i, part := range parts {
+		if part == "" {
+			return nil, tok.Err(diagnostics.UnexpectedToken, "expected a field name after '.' in '%s'", tok.GetLit())
+		}
+
+		partTok := tokens.New(tokens.Ident, part, col, tok.GetLn())
+		if i == 0 {
+			n = nodes.Ident{Tok: partTok}
+		} else {
+			n = nodes.Field{Tok: partTok, Record: n, Name: part}
+		}
+		col += len(part) + 1
+	}
+	return n, nil
+}
+
+func (p *Parser) parseExpr() (nodes.Node, error) {
+	return p.parsePrec(precLowest)
+}
+
+// parsePrec parses an expression made up of operators that bind at least as tightly as min
+func (p *Parser) parsePrec(min precedence) (nodes.Node, error) {
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := infixOps[p.cur().GetKind()]
+		if !ok || op.prec < min {
+			return left, nil
+		}
+
+		tok := p.cur()
+		p.advance()
+
+		next := op.prec + 1
+		if op.assoc == rightAssoc {
+			next = op.prec
+		}
+
+		switch tok.GetKind() {
+		case tokens.Pipe:
+			target, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+
+			switch target.(type) {
+			case nodes.Call, nodes.SelfCall, nodes.Ident:
+			default:
+				return nil, tok.Err(diagnostics.UnexpectedToken, "the right side of '$' must be a function or a call")
+			}
+
+			left = nodes.Pipe{Tok: tok, Value: left, Target: target}
+		case tokens.If:
+			cond, err := p.parsePrec(op.prec + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := p.expect(tokens.Else, "'else'"); err != nil {
+				return nil, err
+			}
+
+			els, err := p.parsePrec(next)
+			if err != nil {
+				return nil, err
+			}
+
+			left = nodes.Conditional{Tok: tok, Then: left, Cond: cond, Else: els}
+		default:
+			right, err := p.parsePrec(next)
+			if err != nil {
+				return nil, err
+			}
+
+			left = nodes.Binary{Op: tok, Left: left, Right: right}
+		}
+	}
+}
+
+func (p *Parser) parsePrefix() (nodes.Node, error) {
+	prec, ok := prefixOps[p.cur().GetKind()]
+	if !ok {
+		return p.parsePostfix()
+	}
+
+	tok := p.cur()
+	p.advance()
+
+	operand, err := p.parsePrec(prec)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes.Unary{Op: tok, Operand: operand}, nil
+}
+
+// parsePostfix parses a primary expression followed by any number of indices, as in `grid [y] [x]` or `(f [x]) [0]`
+func (p *Parser) parsePostfix() (nodes.Node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.cur().IsKind(tokens.OpenBracket) {
+		tok := p.cur()
+		p.advance()
+
+		i, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokens.CloseBracket, "']'"); err != nil {
+			return nil, err
+		}
+		n = nodes.Index{Tok: tok, Value: n, Index: i}
+	}
+	return n, nil
+}
+
+// parseBlock parses the inside of parentheses; a lone expression is just grouped, expressions separated by commas make a tuple,
+// and statements separated by semicolons make a block with its own scope; `()` is the unit value
+func (p *Parser) parseBlock(open tokens.Token) (nodes.Node, error) {
+	body := []nodes.Node{}
+
+	if p.cur().IsKind(tokens.CloseParen) {
+		p.advance()
+		return nodes.Tuple{Tok: open, Elems: body}, nil
+	}
+
+	for {
+		stmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, stmt)
+
+		// a comma after the first expression makes a tuple instead
+		if len(body) == 1 && p.cur().IsKind(tokens.Comma) {
+			switch stmt.(type) {
+			case nodes.FunDecl, nodes.MyDecl, nodes.TypeDecl, nodes.UnionDecl:
+			default:
+				p.advance()
+				rest, err := p.parseList(tokens.CloseParen, "',' or ')'")
+				if err != nil {
+					return nil, err
+				}
+				return nodes.Tuple{Tok: open, Elems: append(body, rest...)}, nil
+			}
+		}
+
+		if p.cur().IsKind(tokens.Semicolon) {
+			p.advance()
+			if !p.cur().IsKind(tokens.CloseParen) {
+				continue
+			}
+		}
+
+		if _, err := p.expect(tokens.CloseParen, "';' or ')'"); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	if len(body) == 1 {
+		switch body[0].(type) {
+		case nodes.FunDecl, nodes.MyDecl:
+		default:
+			return body[0], nil
+		}
+	}
+
+	return nodes.Block{Tok: open, Body: body}, nil
+}
+
+// parseList parses comma-separated expressions up to and including the closing token
+func (p *Parser) parseList(closing tokens.TokenType, what string) ([]nodes.Node, error) {
+	elems := []nodes.Node{}
+
+	if p.cur().IsKind(closing) {
+		p.advance()
+		return elems, nil
+	}
+
+	for {
+		elem, err := p.parseExpr()
+		if err != nil {
+			return []nodes.Node{}, err
+		}
+		elems = append(elems, elem)
+
+		if p.cur().IsKind(tokens.Comma) {
+			p.advance()
+		} else if _, err := p.expect(closing, what); err != nil {
+			return []nodes.Node{}, err
+		} else {
+			return elems, nil
+		}
+	}
+}
+
+// parseMap parses the entries of a map literal, `{key: value, ...}`, after the opening brace
+func (p *Parser) parseMap(open tokens.Token) (nodes.Node, error) {
+	keys, values := []nodes.Node{}, []nodes.Node{}
+
+	if p.cur().IsKind(tokens.CloseBrace) {
+		p.advance()
+		return nodes.Map{Tok: open, Keys: keys, Values: values}, nil
+	}
+
+	for {
+		key, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		} else if _, err := p.expect(tokens.Colon, "':'"); err != nil {
+			return nil, err
+		}
+
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		keys, values = append(keys, key), append(values, value)
+
+		if p.cur().IsKind(tokens.Comma) {
+			p.advance()
+		} else if _, err := p.expect(tokens.CloseBrace, "',' or '}'"); err != nil {
+			return nil, err
+		} else {
+			return nodes.Map{Tok: open, Keys: keys, Values: values}, nil
+		}
+	}
+}
+
+func (p *Parser) parsePrimary() (nodes.Node, error) {
+	tok := p.cur()
+	if p.atEnd() {
+		return nil, p.eofErr()
+	}
+
+	switch tok.GetKind() {
+	case tokens.Number:
+		p.advance()
+		if _, err := tok.Convert(); err != nil {
+			return nil, tok.Err(diagnostics.InvalidNumber, "invalid number literal '%s'", tok.GetLit())
+		}
+		return nodes.Number{Tok: tok}, nil
+	case tokens.String:
+		p.advance()
+		return nodes.String{Tok: tok}, nil
+	case tokens.Bytes:
+		p.advance()
+		return nodes.Bytes{Tok: tok}, nil
+	case tokens.Char:
+		p.advance()
+		return nodes.Char{Tok: tok}, nil
+	case tokens.Bool:
+		p.advance()
+		return nodes.Bool{Tok: tok}, nil
+	case tokens.Arg:
+		p.advance()
+		index, err := strconv.Atoi(tok.GetLit())
+		if err != nil || index < 1 {
+			return nil, tok.Err(diagnostics.InvalidReference, "invalid argument reference '#%s'", tok.GetLit())
+		} else if p.fun == nil {
+			return nil, tok.Err(diagnostics.InvalidReference, "argument reference '#%s' used outside of a function", tok.GetLit())
+		} else if p.fun.params != -1 && index > p.fun.params {
+			return nil, tok.Err(diagnostics.InvalidReference, "argument reference '#%d' is out of range, function '%s' only takes %d parameter(s)", index, p.fun.name, p.fun.params)
+		}
+		p.fun.maxArg = max(p.fun.maxArg, index)
+		return nodes.Arg{Tok: tok, Index: index}, nil
+	case tokens.Ident:
+		p.advance()
+		if strings.Contains(tok.GetLit(), ".") {
+			return p.parseFields(tok)
+		} else if p.cur().IsKind(tokens.OpenBracket) {
+			p.advance()
+			args, err := p.parseList(tokens.CloseBracket, "',' or ']'")
+			if err != nil {
+				return nil, err
+			}
+			return nodes.Call{Tok: tok, Args: args}, nil
+		}
+		return nodes.Ident{Tok: tok}, nil
+	case tokens.Funcall:
+		p.advance()
+		if p.fun == nil {
+			return nil, tok.Err(diagnostics.InvalidReference, "'@%s' used outside of a function", tok.GetLit())
+		} else if tok.GetLit() != p.fun.name {
+			return nil, tok.Err(diagnostics.InvalidReference, "'@%s' used inside of function '%s'", tok.GetLit(), p.fun.name)
+		}
+		if _, err := p.expect(tokens.OpenBracket, "'['"); err != nil {
+			return nil, err
+		}
+		args, err := p.parseList(tokens.CloseBracket, "',' or ']'")
+		if err != nil {
+			return nil, err
+		}
+		return nodes.SelfCall{Tok: tok, Args: args}, nil
+	case tokens.OpenBracket:
+		p.advance()
+		elems, err := p.parseList(tokens.CloseBracket, "',' or ']'")
+		if err != nil {
+			return nil, err
+		}
+		return nodes.List{Tok: tok, Elems: elems}, nil
+	case tokens.OpenVector:
+		p.advance()
+		elems, err := p.parseList(tokens.CloseBracket, "',' or ']'")
+		if err != nil {
+			return nil, err
+		}
+		return nodes.Vector{Tok: tok, Elems: elems}, nil
+	case tokens.OpenSet:
+		p.advance()
+		elems, err := p.parseList(tokens.CloseBrace, "',' or '}'")
+		if err != nil {
+			return nil, err
+		}
+		return nodes.Set{Tok: tok, Elems: elems}, nil
+	case tokens.OpenBrace:
+		p.advance()
+		return p.parseMap(tok)
+	case tokens.Match:
+		p.advance()
+		return p.parseMatch(tok)
+	case tokens.Try:
+		p.advance()
+		return p.parseTry(tok)
+	case tokens.OpenParen:
+		p.advance()
+		return p.parseBlock(tok)
+	}
+
+	return nil, p.unexpected()
+}
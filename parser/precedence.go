@@ -0,0 +1,80 @@
+package parser
+
+import "github.com/voidwyrm-2/opal/lexer/tokens"
+
+type precedence uint8
+
+/*
+From loosest to tightest:
+
+	$                     pipe            left
+	x if cond else y      conditional     right
+	or                                    left
+	and                                   left
+	not                   prefix
+	== != < > <= >=       comparison      left
+	+ - ++ | ^            additive        left
+	* / % &               multiplicative  left
+	-                     prefix
+
+Calls, `@name` calls, indexing such as `xs [i]`, literals and parentheses bind tighter than all of them.
+Like Go, the bitwise operators share a level with the arithmetic ones,
+so `x & 1 == 0` means `(x & 1) == 0`.
+*/
+const (
+	precLowest precedence = iota
+	precPipe
+	precConditional
+	precOr
+	precAnd
+	precNot
+	precComparison
+	precAdditive
+	precMultiplicative
+	precNegate
+)
+
+type associativity uint8
+
+const (
+	leftAssoc associativity = iota
+	rightAssoc
+)
+
+type infixOp struct {
+	prec  precedence
+	assoc associativity
+}
+
+var infixOps = map[tokens.TokenType]infixOp{
+	tokens.Pipe: {precPipe, leftAssoc},
+
+	tokens.If: {precConditional, rightAssoc},
+
+	tokens.Or:  {precOr, leftAssoc},
+	tokens.And: {precAnd, leftAssoc},
+
+	tokens.Equals:               {precComparison, leftAssoc},
+	tokens.NotEquals:            {precComparison, leftAssoc},
+	tokens.GreaterThan:          {precComparison, leftAssoc},
+	tokens.LesserThan:           {precComparison, leftAssoc},
+	tokens.GreaterThanOrEqualTo: {precComparison, leftAssoc},
+	tokens.LesserThanOrEqualTo:  {precComparison, leftAssoc},
+
+	tokens.Plus:   {precAdditive, leftAssoc},
+	tokens.Hyphen: {precAdditive, leftAssoc},
+	tokens.Concat: {precAdditive, leftAssoc},
+	tokens.BitOr:  {precAdditive, leftAssoc},
+	tokens.BitXOR: {precAdditive, leftAssoc},
+
+	tokens.Asterisk:     {precMultiplicative, leftAssoc},
+	tokens.ForwardSlash: {precMultiplicative, leftAssoc},
+	tokens.Percent:      {precMultiplicative, leftAssoc},
+	tokens.BitAnd:       {precMultiplicative, leftAssoc},
+}
+
+// prefixOps maps each prefix operator to the precedence its operand is parsed at
+var prefixOps = map[tokens.TokenType]precedence{
+	tokens.Not:    precNot,
+	tokens.Hyphen: precNegate,
+}
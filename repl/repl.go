@@ -0,0 +1,185 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/voidwyrm-2/opal/checker"
+	"github.com/voidwyrm-2/opal/diagnostics"
+	"github.com/voidwyrm-2/opal/interpreter"
+	"github.com/voidwyrm-2/opal/interpreter/valuetypes/tupletype"
+	"github.com/voidwyrm-2/opal/lexer"
+	"github.com/voidwyrm-2/opal/lexer/tokens"
+	"github.com/voidwyrm-2/opal/parser"
+	"github.com/voidwyrm-2/opal/parser/nodes"
+)
+
+const help = `Input is run once it ends with a ';', and may span several lines.
+
+:tokens <code>   print the tokens of some code
+:ast <code>      print the parse tree of some code
+:type <code>     print the inferred type of an expression
+:load <file>     run a file, keeping its definitions
+:help            print this message
+:quit            leave the REPL`
+
+/*
+Repl keeps one interpreter and one checker running across inputs.
+Every input is run in a scope nested inside of the previous one,
+so definitions can be shadowed by later ones, while the functions that used the old ones still see them.
+*/
+type Repl struct {
+	interp  *interpreter.Interpreter
+	checker *checker.Checker
+	out     io.Writer
+}
+
+func New(out io.Writer) *Repl {
+	return &Repl{interp: interpreter.New(), checker: checker.New(), out: out}
+}
+
+func lex(src string) ([]tokens.Token, error) {
+	l := lexer.New(src)
+	return l.Lex()
+}
+
+func parse(src string) ([]nodes.Node, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return []nodes.Node{}, err
+	}
+
+	p := parser.New(toks)
+	return p.Parse()
+}
+
+func (r *Repl) Run(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	buf := ""
+
+	fmt.Fprintln(r.out, "Opal REPL; type :help for help")
+
+	for {
+		if buf == "" {
+			fmt.Fprint(r.out, "opal> ")
+		} else {
+			fmt.Fprint(r.out, "  ... ")
+		}
+
+		if !scanner.Scan() {
+			fmt.Fprintln(r.out)
+			return
+		}
+		line := scanner.Text()
+
+		if buf == "" && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			if !r.command(strings.TrimSpace(line)) {
+				return
+			}
+			continue
+		}
+
+		buf += line + "\n"
+		if strings.HasSuffix(strings.TrimSpace(buf), ";") {
+			r.run(buf, true)
+			buf = ""
+		}
+	}
+}
+
+// command runs a meta-command, and reports whether the REPL should keep going
+func (r *Repl) command(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case ":quit", ":q":
+		return false
+	case ":help":
+		fmt.Fprintln(r.out, help)
+	case ":tokens":
+		toks, err := lex(arg)
+		if err != nil {
+			r.report(arg, err)
+			break
+		}
+		for _, t := range toks {
+			fmt.Fprintln(r.out, t.Str())
+		}
+	case ":ast":
+		program, err := parse(arg)
+		if err != nil {
+			r.report(arg, err)
+			break
+		}
+		for _, n := range program {
+			fmt.Fprintln(r.out, n.Str())
+		}
+	case ":type":
+		program, err := parse(arg)
+		if err != nil {
+			r.report(arg, err)
+			break
+		} else if len(program) == 0 {
+			fmt.Fprintln(r.out, "nothing to infer the type of")
+			break
+		}
+
+		t, errs := r.checker.TypeOf(program[len(program)-1])
+		for _, err := range errs {
+			r.report(arg, err)
+		}
+		if !diagnostics.AnyErrors(errs) {
+			fmt.Fprintln(r.out, checker.Show(t))
+		}
+	case ":load":
+		content, err := os.ReadFile(arg)
+		if err != nil {
+			r.report("", err)
+			break
+		}
+		r.run(string(content), false)
+	default:
+		fmt.Fprintf(r.out, "unknown command '%s'; type :help for help\n", name)
+	}
+
+	return true
+}
+
+// report prints an error along with the line of src it points at
+func (r *Repl) report(src string, err error) {
+	fmt.Fprint(r.out, diagnostics.From(err).Render(src))
+}
+
+// run runs some code, printing the value of the last statement if show is set
+func (r *Repl) run(src string, show bool) {
+	program, err := parse(src)
+	if err != nil {
+		r.report(src, err)
+		return
+	}
+
+	r.interp.NewScope()
+	result, err := r.interp.Eval(program)
+	if err != nil {
+		r.report(src, err)
+		return
+	}
+
+	// the checker only needs to learn the new definitions; its complaints are for :type and `opal check`
+	r.checker.Check(program)
+
+	if show && len(program) != 0 {
+		switch program[len(program)-1].(type) {
+		case nodes.FunDecl, nodes.MyDecl, nodes.TypeDecl, nodes.UnionDecl:
+		default:
+			// unit means there's nothing to show, as after `say`
+			if t, ok := result.(tupletype.TupleType); !ok || !t.IsUnit() {
+				fmt.Fprintln(r.out, result.Fmt())
+			}
+		}
+	}
+}